@@ -0,0 +1,139 @@
+// Package acl implements CIDR-based access control for listeners and gRPC calls.
+package acl
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// List is a set of allowed and denied networks.
+//
+// An address is allowed if it does not match any denied network and,
+// if the allowed networks are specified, matches one of them.
+type List struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// NewList returns a new List from the given lists of networks in CIDR notation.
+// Single IP addresses are also accepted.
+func NewList(allow, deny []string) (*List, error) {
+	l := new(List)
+
+	for _, s := range allow {
+		p, err := ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+
+		l.allow = append(l.allow, p)
+	}
+
+	for _, s := range deny {
+		p, err := ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+
+		l.deny = append(l.deny, p)
+	}
+
+	return l, nil
+}
+
+// Empty reports whether the list has no networks and therefore allows everything.
+func (l *List) Empty() bool {
+	return l == nil || (len(l.allow) == 0 && len(l.deny) == 0)
+}
+
+// Allowed reports whether a given address is allowed by the list.
+func (l *List) Allowed(addr netip.Addr) bool {
+	if l.Empty() {
+		return true
+	}
+
+	addr = addr.Unmap()
+
+	for _, p := range l.deny {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+
+	if len(l.allow) == 0 {
+		return true
+	}
+
+	for _, p := range l.allow {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowedAddr is like [List.Allowed] but accepts a [net.Addr].
+// Addresses that have no IP (e.g. Unix sockets) are always allowed.
+func (l *List) AllowedAddr(addr net.Addr) bool {
+	if ip, ok := AddrIP(addr); ok {
+		return l.Allowed(ip)
+	}
+
+	return true
+}
+
+// ParsePrefix parses a network in CIDR notation or a single IP address.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid network: %w", err)
+		}
+
+		if p.Addr().Is4In6() {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+
+		return p.Masked(), nil
+	}
+
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network: %w", err)
+	}
+
+	ip = ip.Unmap()
+
+	return netip.PrefixFrom(ip, ip.BitLen()), nil
+}
+
+// AddrIP returns the IP address of a given [net.Addr].
+// The second return value reports whether the address has an IP.
+func AddrIP(addr net.Addr) (netip.Addr, bool) {
+	switch v := addr.(type) {
+	case nil:
+		return netip.Addr{}, false
+	case *net.TCPAddr:
+		ip, ok := netip.AddrFromSlice(v.IP)
+
+		return ip.Unmap(), ok
+	case *net.UDPAddr:
+		ip, ok := netip.AddrFromSlice(v.IP)
+
+		return ip.Unmap(), ok
+	case *net.UnixAddr:
+		return netip.Addr{}, false
+	}
+
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ap.Addr().Unmap(), true
+}
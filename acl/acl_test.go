@@ -0,0 +1,75 @@
+package acl
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestListAllowed(t *testing.T) {
+	list, err := NewList([]string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"}, []string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.2.3.4", true},
+		{"10.1.2.3", false},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"::ffff:10.2.3.4", true},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+	}
+
+	for _, tt := range tests {
+		if got := list.Allowed(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.addr, got, tt.want)
+		}
+	}
+
+	if !list.AllowedAddr(&net.UnixAddr{Name: "/run/test.sock", Net: "unix"}) {
+		t.Errorf("unix socket address must be allowed")
+	}
+
+	if !(*List)(nil).Allowed(netip.MustParseAddr("1.2.3.4")) {
+		t.Errorf("empty list must allow everything")
+	}
+
+	if _, err := NewList([]string{"10.0.0.0/33"}, nil); err == nil {
+		t.Errorf("expected an error for invalid network")
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	r, err := ParseRule("bucket:admin deny 203.0.113.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.Match("/pkg.Svc/Get", []string{"default", "admin"}) || r.Match("/pkg.Svc/Get", []string{"default"}) {
+		t.Errorf("unexpected bucket rule matching")
+	}
+
+	if r.List.Allowed(netip.MustParseAddr("203.0.113.5")) {
+		t.Errorf("address must be denied by the rule")
+	}
+
+	r, err = ParseRule("/pkg.Svc/ allow 10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.Match("/pkg.Svc/Get", nil) || r.Match("/pkg.Other/Get", nil) {
+		t.Errorf("unexpected service rule matching")
+	}
+
+	for _, s := range []string{"", "bucket:x allow", "svc allow 10.0.0.0/8", "/pkg.Svc/ permit 10.0.0.0/8"} {
+		if _, err := ParseRule(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package acl
+
+import (
+	"net"
+)
+
+type listener struct {
+	net.Listener
+
+	list     *List
+	onReject func(net.Conn)
+}
+
+// NewListener returns a listener that closes incoming connections
+// from the addresses not allowed by a given list.
+//
+// The onReject function, if not nil, is called for each rejected connection
+// before it is closed.
+func NewListener(l net.Listener, list *List, onReject func(net.Conn)) net.Listener {
+	if list.Empty() {
+		return l
+	}
+
+	return &listener{
+		Listener: l,
+		list:     list,
+		onReject: onReject,
+	}
+}
+
+// Accept waits for and returns the next allowed connection.
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.list.AllowedAddr(c.RemoteAddr()) {
+			return c, nil
+		}
+
+		if l.onReject != nil {
+			l.onReject(c)
+		}
+
+		c.Close()
+	}
+}
@@ -0,0 +1,78 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule restricts access to a bucket, a service or a method.
+type Rule struct {
+	// Target is a bucket name prefixed with "bucket:", a service name
+	// in the form "/package.Service/" or a full method name
+	// in the form "/package.Service/Method".
+	Target string
+
+	// List contains the networks of the rule.
+	List *List
+}
+
+// ParseRule parses a rule in the form "<target> allow|deny <network>[,<network>...]".
+//
+// Examples:
+//
+//	bucket:admin allow 10.0.0.0/8,192.168.1.10
+//	/pkg.Service/ deny 203.0.113.0/24
+//	/pkg.Service/Delete allow 10.1.0.0/16
+func ParseRule(s string) (*Rule, error) {
+	fields := strings.Fields(s)
+
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid access rule %q: expected \"<target> allow|deny <networks>\"", s)
+	}
+
+	target := fields[0]
+
+	if !strings.HasPrefix(target, "bucket:") && !strings.HasPrefix(target, "/") {
+		return nil, fmt.Errorf("invalid access rule %q: unknown target type", s)
+	}
+
+	networks := strings.Split(fields[2], ",")
+
+	var list *List
+	var err error
+
+	switch fields[1] {
+	case "allow":
+		list, err = NewList(networks, nil)
+	case "deny":
+		list, err = NewList(nil, networks)
+	default:
+		return nil, fmt.Errorf("invalid access rule %q: unknown action %q", s, fields[1])
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid access rule %q: %w", s, err)
+	}
+
+	return &Rule{Target: target, List: list}, nil
+}
+
+// Match reports whether the rule applies to a given method
+// that belongs to the given buckets.
+func (r *Rule) Match(fullMethod string, buckets []string) bool {
+	if bucket, ok := strings.CutPrefix(r.Target, "bucket:"); ok {
+		for _, b := range buckets {
+			if b == bucket {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if strings.HasSuffix(r.Target, "/") {
+		return strings.HasPrefix(fullMethod, r.Target)
+	}
+
+	return r.Target == fullMethod
+}
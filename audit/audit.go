@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/0xef53/go-grpc/utils"
+)
+
+// Record represents a single audit record describing one RPC call.
+//
+// Request and response payloads are stored as tags redacted according to
+// the properties of [options.FieldLogging].
+type Record struct {
+	Time      time.Time              `json:"time"`
+	Method    string                 `json:"method"`
+	Caller    Caller                 `json:"caller"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Code      string                 `json:"code"`
+	Error     string                 `json:"error,omitempty"`
+	Duration  float64                `json:"duration_ms"`
+	Request   map[string]interface{} `json:"request,omitempty"`
+	Response  map[string]interface{} `json:"response,omitempty"`
+}
+
+// Caller represents the identity of the RPC caller.
+type Caller struct {
+	// Addr is a network address of the client (see [utils.ClientAddr]).
+	Addr string `json:"addr,omitempty"`
+
+	// Identity is a subject common name of the peer TLS certificate (if any).
+	Identity string `json:"identity,omitempty"`
+}
+
+// CallerFromContext returns the caller identity obtained from the peer information
+// stored in the context. The calls received from the gRPC Gateway are attributed
+// to the client of the gateway, as in the access lists.
+func CallerFromContext(ctx context.Context) Caller {
+	var c Caller
+
+	if addr, ok := utils.ClientAddr(ctx); ok {
+		c.Addr = addr.String()
+	}
+
+	c.Identity, _ = utils.PeerIdentity(ctx)
+
+	return c
+}
+
+// Sink is a common interface for audit record destinations.
+type Sink interface {
+	Write(*Record) error
+	Close() error
+}
+
+// WriterSink is a [Sink] that writes records in JSON-lines format
+// to the underlying writer.
+type WriterSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterSink returns a new [WriterSink] writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{
+		enc: json.NewEncoder(w),
+	}
+}
+
+// Write writes a given record as a single JSON line.
+func (s *WriterSink) Write(rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.enc.Encode(rec)
+}
+
+// Close does nothing and exists to satisfy the [Sink] interface.
+func (s *WriterSink) Close() error {
+	return nil
+}
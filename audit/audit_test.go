@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/0xef53/go-grpc/utils"
+
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_peer "google.golang.org/grpc/peer"
+)
+
+func TestCallerFromGateway(t *testing.T) {
+	md, _ := grpc_metadata.FromOutgoingContext(utils.WithGatewayToken(context.Background()))
+
+	md.Set("x-forwarded-for", "203.0.113.1")
+
+	ctx := grpc_peer.NewContext(context.Background(), &grpc_peer.Peer{Addr: &net.UnixAddr{Name: "@app.sock", Net: "unix"}})
+	ctx = grpc_metadata.NewIncomingContext(ctx, md)
+
+	// The call is attributed to the client of the gateway
+	if c := CallerFromContext(ctx); c.Addr != "203.0.113.1:0" {
+		t.Fatalf("got invalid caller address: want 203.0.113.1:0, got %s", c.Addr)
+	}
+}
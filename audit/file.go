@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSink is a [Sink] that writes records in JSON-lines format to a file
+// and rotates it when its size exceeds the limit.
+//
+// Rotated files are renamed by adding a numeric suffix: "audit.log.1" is the most
+// recent one, "audit.log.2" is older and so on.
+type FileSink struct {
+	mu sync.Mutex
+
+	filename   string
+	maxSize    int64
+	maxBackups int
+
+	fd   *os.File
+	size int64
+}
+
+// NewFileSink opens (or creates) a given file and returns a new [FileSink].
+//
+// If maxSize is zero, the file is never rotated. The maxBackups specifies
+// the number of rotated files to keep.
+func NewFileSink(filename string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{
+		filename:   filename,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	fd, err := os.OpenFile(s.filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	fi, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+
+		return err
+	}
+
+	s.fd = fd
+	s.size = fi.Size()
+
+	return nil
+}
+
+// rotate closes the current file, shifts the backups and opens a new file.
+func (s *FileSink) rotate() (err error) {
+	if err := s.fd.Close(); err != nil {
+		return err
+	}
+
+	defer func() {
+		// The file must be reopened even if the backups could not be shifted
+		if e := s.open(); e != nil {
+			s.fd = nil
+
+			if err == nil {
+				err = e
+			}
+		}
+	}()
+
+	if s.maxBackups == 0 {
+		return os.Remove(s.filename)
+	}
+
+	for n := s.maxBackups - 1; n > 0; n-- {
+		src := fmt.Sprintf("%s.%d", s.filename, n)
+
+		if _, err := os.Stat(src); err == nil {
+			if err := os.Rename(src, fmt.Sprintf("%s.%d", s.filename, n+1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return os.Rename(s.filename, s.filename+".1")
+}
+
+// Write writes a given record as a single JSON line.
+func (s *FileSink) Write(rec *Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fd == nil {
+		return os.ErrClosed
+	}
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(b)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.fd.Write(b)
+
+	s.size += int64(n)
+
+	return err
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fd == nil {
+		return nil
+	}
+
+	err := s.fd.Close()
+
+	s.fd = nil
+
+	return err
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSinkRotation(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileSink(filename, 256, 2)
+	if err != nil {
+		t.Fatalf("cannot create file sink: %s", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := sink.Write(&Record{Method: "/test.v1.Service/Method", Code: "OK"}); err != nil {
+			t.Fatalf("cannot write record (idx == %d): %s", i, err)
+		}
+	}
+
+	for _, name := range []string{filename, filename + ".1", filename + ".2"} {
+		fd, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("expected file does not exist: %s", err)
+		}
+
+		scanner := bufio.NewScanner(fd)
+
+		for scanner.Scan() {
+			var rec Record
+
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				t.Fatalf("invalid JSON line in %s: %s", name, err)
+			}
+		}
+
+		fd.Close()
+	}
+
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("got unexpected backup file: %s.3", filename)
+	}
+}
@@ -1,70 +1,429 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/0xef53/go-grpc/client/interceptors"
+	"github.com/0xef53/go-grpc/client/resolver"
+	"github.com/0xef53/go-grpc/logging"
 	"github.com/0xef53/go-grpc/utils"
 
+	// Register the supported compressors
+	_ "github.com/0xef53/go-grpc/encoding/zstd"
+	_ "google.golang.org/grpc/encoding/gzip"
+
+	// Register the client-side health checking function
+	_ "google.golang.org/grpc/health"
+
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	grpc_resolver "google.golang.org/grpc/resolver"
 
 	log "github.com/sirupsen/logrus"
 )
 
-var logger = log.StandardLogger().WithField("subsystem", "client")
+var logger = logging.NewLogrusLogger(log.StandardLogger().WithField("subsystem", "client"))
 
 // SetLogger sets the global logger used by the package's entities.
 // It should be called during initialization, and it is strongly recommended
 // not to change it afterward.
 func SetLogger(entry *log.Entry) {
-	logger = entry
+	logger = logging.NewLogrusLogger(entry)
+}
+
+// SetLoggerAdapter is like [SetLogger] but accepts any [logging.Logger]
+// implementation, e.g. the one returned by [logging.NewSlogLogger].
+func SetLoggerAdapter(l logging.Logger) {
+	logger = l
+}
+
+// WithCompression returns a dial option that sets the compressor used for the requests
+// by default, e.g. "gzip" or "zstd". The method option of type [options.MethodPolicy]
+// and the [grpc.UseCompressor] call option take precedence over this value.
+func WithCompression(name string) grpc.DialOption {
+	return grpc.WithDefaultCallOptions(interceptors.UseDefaultCompressor(name))
+}
+
+const (
+	// RoundRobin is a load balancing policy that distributes calls across
+	// all healthy endpoints.
+	RoundRobin = "round_robin"
+
+	// PickFirst is a load balancing policy that sends all calls to the first
+	// available endpoint and switches to the next one when it fails.
+	PickFirst = "pick_first"
+
+	// WeightedRoundRobin is a load balancing policy that distributes calls across
+	// all healthy endpoints in proportion to their weights from the endpoints file
+	// (see [resolver.WeightedRoundRobin]).
+	WeightedRoundRobin = resolver.WeightedRoundRobin
+)
+
+// WithLoadBalancing returns a dial option that sets the load balancing policy
+// ([RoundRobin], [WeightedRoundRobin] or [PickFirst]) for connections to multiple endpoints.
+//
+// With the RoundRobin and WeightedRoundRobin policies, the endpoints are also checked
+// using the standard health checking service, and calls are sent only to the serving ones.
+func WithLoadBalancing(policy string) grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(serviceConfig(policy))
+}
+
+func serviceConfig(policy string) string {
+	if policy == RoundRobin || policy == WeightedRoundRobin {
+		return fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}], "healthCheckConfig": {"serviceName": ""}}`, policy)
+	}
+
+	return fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, policy)
+}
+
+// ErrNotReady is returned by the connection functions if the connection
+// is not ready within the timeout set by [WaitUntilReady].
+var ErrNotReady = errors.New("connection is not ready")
+
+// readinessDialOption is handled by the connection functions and is ignored by gRPC.
+type readinessDialOption struct {
+	grpc.EmptyDialOption
+
+	timeout time.Duration
+}
+
+// WaitUntilReady returns a dial option that makes the connection functions
+// connect immediately and wait until the connection is ready for a given timeout.
+// Otherwise, the connection is closed and an error wrapping [ErrNotReady]
+// with the last error of the name resolution, dialing or TLS handshake is returned.
+//
+// To catch these errors, the option installs its own dialer (see [grpc.WithContextDialer]),
+// so the proxy settings from the environment are not used. A dialer, transport
+// credentials or resolver passed in the dial options replace the ones of the option.
+//
+// By default, the connection is established on the first call, so that an invalid
+// address is reported only by the call.
+func WaitUntilReady(timeout time.Duration) grpc.DialOption {
+	return readinessDialOption{timeout: timeout}
+}
+
+// waitUntilReady waits until a given connection is ready.
+func waitUntilReady(conn *grpc.ClientConn, errs *connErrors, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn.Connect()
+
+	for {
+		state := conn.GetState()
+
+		if state == connectivity.Ready {
+			return nil
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			break
+		}
+	}
+
+	state := conn.GetState()
+
+	if lastErr := errs.last(); state == connectivity.TransientFailure && lastErr != nil {
+		return fmt.Errorf("%w: %s within %s (state %s): %s", ErrNotReady, conn.Target(), timeout, state, lastErr)
+	}
+
+	return fmt.Errorf("%w: %s within %s (state %s)", ErrNotReady, conn.Target(), timeout, state)
+}
+
+// connErrors remembers the last error that prevented a connection from becoming ready,
+// since gRPC does not expose it. The errors of the name resolution, dialing
+// and TLS handshake are caught.
+type connErrors struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (e *connErrors) record(err error) {
+	if err == nil {
+		// The success of another endpoint must not hide the error
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.err = err
+}
+
+func (e *connErrors) last() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.err
+}
+
+// dial establishes the transport connections in the same way as the default
+// gRPC dialer, except that the proxy settings from the environment are not used.
+func (e *connErrors) dial(ctx context.Context, addr string) (net.Conn, error) {
+	network := "tcp"
+
+	// See the special handling of the unix addresses in the gRPC transport
+	switch {
+	case strings.HasPrefix(addr, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(addr, "unix://")
+	case strings.HasPrefix(addr, "unix:"):
+		network, addr = "unix", strings.TrimPrefix(addr, "unix:")
+	case strings.HasPrefix(addr, "\x00"):
+		network = "unix"
+	}
+
+	conn, err := new(net.Dialer).DialContext(ctx, network, addr)
+
+	e.record(err)
+
+	return conn, err
+}
+
+// credentials returns transport credentials that record the handshake errors.
+func (e *connErrors) credentials(c credentials.TransportCredentials) credentials.TransportCredentials {
+	return &recordingCredentials{TransportCredentials: c, errs: e}
+}
+
+// resolvers returns the resolver builders for a given target that record
+// the name resolution errors.
+//
+// Targets without a registered scheme are resolved using the default scheme,
+// which is "dns" unless it is changed by [grpc_resolver.SetDefaultScheme].
+// So the builders of both are returned, and gRPC uses the appropriate one.
+func (e *connErrors) resolvers(target string) []grpc_resolver.Builder {
+	if u, err := url.Parse(target); err == nil {
+		if b := grpc_resolver.Get(u.Scheme); b != nil {
+			return []grpc_resolver.Builder{&recordingResolverBuilder{Builder: b, errs: e}}
+		}
+	}
+
+	var builders []grpc_resolver.Builder
+
+	for _, scheme := range []string{"dns", grpc_resolver.GetDefaultScheme()} {
+		if b := grpc_resolver.Get(scheme); b != nil {
+			builders = append(builders, &recordingResolverBuilder{Builder: b, errs: e})
+		}
+	}
+
+	return builders
+}
+
+type recordingCredentials struct {
+	credentials.TransportCredentials
+
+	errs *connErrors
+}
+
+func (c *recordingCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+
+	c.errs.record(err)
+
+	return conn, info, err
+}
+
+func (c *recordingCredentials) Clone() credentials.TransportCredentials {
+	return &recordingCredentials{TransportCredentials: c.TransportCredentials.Clone(), errs: c.errs}
+}
+
+type recordingResolverBuilder struct {
+	grpc_resolver.Builder
+
+	errs *connErrors
+}
+
+func (b *recordingResolverBuilder) Build(target grpc_resolver.Target, cc grpc_resolver.ClientConn, opts grpc_resolver.BuildOptions) (grpc_resolver.Resolver, error) {
+	return b.Builder.Build(target, &recordingResolverConn{ClientConn: cc, target: target, errs: b.errs}, opts)
+}
+
+type recordingResolverConn struct {
+	grpc_resolver.ClientConn
+
+	target grpc_resolver.Target
+	errs   *connErrors
+}
+
+func (c *recordingResolverConn) UpdateState(state grpc_resolver.State) error {
+	// E.g., the DNS resolver reports a non-existent host in this way
+	if len(state.Addresses) == 0 && len(state.Endpoints) == 0 {
+		c.errs.record(fmt.Errorf("no addresses resolved for %q", c.target.Endpoint()))
+	}
+
+	return c.ClientConn.UpdateState(state)
+}
+
+func (c *recordingResolverConn) ReportError(err error) {
+	c.errs.record(err)
+
+	c.ClientConn.ReportError(err)
 }
 
 // newConnection creates and configures a new gRPC client connection to the specified host:port
 // according to the passed arguments.
 func newConnection(hostport string, tlsConfig *tls.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
-	hostport = utils.NormalizeHostport(hostport)
+	var target string
+	var policy string
+
+	switch endpoints := strings.Split(hostport, ","); {
+	case utils.HasScheme(hostport):
+		target = utils.NormalizeTarget(hostport)
+
+		// The endpoints and their weights are read from the file
+		if strings.HasPrefix(target, resolver.FileScheme+"://") {
+			policy = WeightedRoundRobin
+		}
+	case len(endpoints) > 1:
+		for idx := range endpoints {
+			endpoints[idx] = utils.NormalizeHostport(endpoints[idx])
+		}
+
+		target, policy = resolver.StaticTarget(endpoints...), RoundRobin
+	default:
+		target = utils.NormalizeTarget(hostport)
+	}
 
 	dialOpts := []grpc.DialOption{
 		grpc.WithChainUnaryInterceptor(
 			interceptors.WithRequestIdentifier(),
-			interceptors.WithRequestLogging(logger),
+			interceptors.WithRequestLoggingAdapter(logger),
+			interceptors.WithCompression(),
+			interceptors.WithHedging(logger),
 		),
 		grpc.WithChainStreamInterceptor(
 			interceptors.WithStreamRequestIdentifier(),
-			interceptors.WithStreamRequestLogging(logger),
+			interceptors.WithStreamRequestLoggingAdapter(logger),
+			interceptors.WithStreamCompression(),
 		),
 	}
 
+	var readiness time.Duration
+
+	for _, o := range opts {
+		if v, ok := o.(readinessDialOption); ok {
+			readiness = v.timeout
+		}
+	}
+
+	// Used to report the last error if the connection is not ready
+	errs := new(connErrors)
+
+	var creds credentials.TransportCredentials
+
 	if tlsConfig == nil {
 		// Insecure connection
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		creds = insecure.NewCredentials()
 	} else {
 		// Secure connection
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	if readiness > 0 {
+		creds = errs.credentials(creds)
+
+		dialOpts = append(dialOpts, grpc.WithContextDialer(errs.dial))
+	}
+
+	dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+
+	if len(policy) > 0 {
+		dialOpts = append(dialOpts, WithLoadBalancing(policy))
 	}
 
 	dialOpts = append(dialOpts, opts...)
 
-	return grpc.Dial(hostport, dialOpts...)
+	if readiness > 0 {
+		// Added after the passed options, since the first matching resolver is used
+		dialOpts = append(dialOpts, grpc.WithResolvers(errs.resolvers(target)...))
+	}
+
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if readiness > 0 {
+		if err := waitUntilReady(conn, errs, readiness); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	return conn, nil
 }
 
 // NewSecureConnection returns a secure gRPC client connection to the specified host:port.
+// Targets with a scheme, e.g. "unix:/run/app.sock" or "dns:///example.org",
+// are also supported (see [utils.NormalizeTarget]).
+//
+// Multiple comma-separated endpoints can be specified. In this case, calls are balanced
+// across the healthy endpoints (see [WithLoadBalancing]). The endpoints can also be read
+// from a file using the "file://" target, e.g. "file:///etc/app/endpoints.json"
+// (see [resolver.ReadEndpointsFile] for the format), in which case calls are balanced
+// according to the endpoint weights.
 //
-// When configuring the connection, two mandatory unary and stream interceptors are used
-// to handle the request ID and log the request parameters.
+// When configuring the connection, mandatory unary and stream interceptors are used
+// to handle the request ID, log the request parameters, select the compressor
+// and send hedged requests (see [interceptors.WithHedging]).
 // Additional dial options can be provided using arguments.
+//
+// The connection is established on the first call unless the [WaitUntilReady]
+// option is used.
 func NewSecureConnection(hostport string, tlsConfig *tls.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	return newConnection(hostport, tlsConfig, opts...)
 }
 
 // NewInsecureConnection returns an insecure gRPC client connection to the specified host:port.
+// Targets with a scheme are also supported (see [NewSecureConnection]).
+//
+// Multiple comma-separated endpoints can be specified. In this case, calls are balanced
+// across the healthy endpoints (see [WithLoadBalancing]). The endpoints can also be read
+// from a file using the "file://" target, e.g. "file:///etc/app/endpoints.json"
+// (see [resolver.ReadEndpointsFile] for the format), in which case calls are balanced
+// according to the endpoint weights.
 //
-// When configuring the connection, two mandatory unary and stream interceptors are used
-// to handle the request ID and log the request parameters.
+// When configuring the connection, mandatory unary and stream interceptors are used
+// to handle the request ID, log the request parameters, select the compressor
+// and send hedged requests (see [interceptors.WithHedging]).
 // Additional dial options can be provided using arguments.
 func NewInsecureConnection(hostport string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	return newConnection(hostport, nil, opts...)
 }
+
+// NewLocalConnection returns a gRPC client connection to the default unix socket
+// of a running instance of a given program on the local host (see [utils.LocalSocketTarget]).
+// If tlsConfig is nil, the connection is insecure.
+//
+// It allows CLIs to talk to their daemon without any configuration.
+func NewLocalConnection(program string, tlsConfig *tls.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	target, err := utils.LocalSocketTarget(program)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConnection(target, tlsConfig, opts...)
+}
+
+// NewSecureBalancedConnection returns a secure gRPC client connection to the specified list
+// of endpoints ("host:port" pairs). Calls are balanced across the healthy endpoints
+// using the round-robin policy. The policy can be changed using [WithLoadBalancing].
+func NewSecureBalancedConnection(endpoints []string, tlsConfig *tls.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return newConnection(strings.Join(endpoints, ","), tlsConfig, opts...)
+}
+
+// NewInsecureBalancedConnection returns an insecure gRPC client connection to the specified list
+// of endpoints ("host:port" pairs). Calls are balanced across the healthy endpoints
+// using the round-robin policy. The policy can be changed using [WithLoadBalancing].
+func NewInsecureBalancedConnection(endpoints []string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return newConnection(strings.Join(endpoints, ","), nil, opts...)
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestWaitUntilReady(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	conn, err := NewInsecureConnection(l.Addr().String(), WaitUntilReady(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Close()
+
+	// Nobody listens on the port anymore
+	l2, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l2.Addr().String()
+	l2.Close()
+
+	// The transport error must be obtained without calls
+	calls := 0
+
+	counter := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		calls++
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	_, err = NewInsecureConnection(addr, WaitUntilReady(200*time.Millisecond), grpc.WithChainUnaryInterceptor(counter))
+	if !errors.Is(err, ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("the error does not describe the transport error: %s", err)
+	}
+
+	if calls != 0 {
+		t.Fatalf("got invalid number of calls: want 0, got %d", calls)
+	}
+}
+
+func TestWaitUntilReadyUnix(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "app.sock")
+
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	conn, err := NewInsecureConnection("unix:"+sock, WaitUntilReady(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Close()
+}
+
+func TestWaitUntilReadyResolverError(t *testing.T) {
+	_, err := NewInsecureConnection("no-such-host.invalid:9191", WaitUntilReady(time.Second))
+	if !errors.Is(err, ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), `no addresses resolved for "no-such-host.invalid:9191"`) {
+		t.Fatalf("the error does not describe the resolver error: %s", err)
+	}
+}
+
+func TestWaitUntilReadyHandshakeError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server does not use TLS
+	srv := grpc.NewServer()
+
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	_, err = NewSecureConnection(l.Addr().String(), &tls.Config{InsecureSkipVerify: true}, WaitUntilReady(time.Second))
+	if !errors.Is(err, ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "tls:") {
+		t.Fatalf("the error does not describe the handshake error: %s", err)
+	}
+}
@@ -0,0 +1,266 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xef53/go-grpc/client/interceptors"
+	"github.com/0xef53/go-grpc/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Duration is a [time.Duration] that is read from and written to the config files
+// in the text form, e.g. "1.5s" or "300ms".
+type Duration time.Duration
+
+// MarshalText implements [encoding.TextMarshaler].
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// Config represents a gRPC client config.
+type Config struct {
+	// Targets specifies the server endpoints ("host:port" pairs). Calls are balanced
+	// across multiple endpoints (see [NewSecureConnection]). A single target with
+	// a scheme can be used instead, e.g. "unix:/run/app.sock" or "file:///etc/app/endpoints.json"
+	// to read the endpoints from a file.
+	Targets []string `gcfg:"target" ini:"target,,allowshadow" json:"targets"`
+
+	// TLS enables TLS encryption for the connection. It is implicitly enabled
+	// if any of the TLS files is set. If TLSCACert is empty, the server certificate
+	// is verified using the system roots.
+	TLS bool `gcfg:"tls" ini:"tls" json:"tls"`
+
+	// TLSCACert, TLSCert and TLSKey specify the paths to PEM-encoded files
+	// with the CA certificates, the client certificate and its private key.
+	TLSCACert string `gcfg:"tls-ca-cert" ini:"tls-ca-cert" json:"tls_ca_cert"`
+	TLSCert   string `gcfg:"tls-cert" ini:"tls-cert" json:"tls_cert"`
+	TLSKey    string `gcfg:"tls-key" ini:"tls-key" json:"tls_key"`
+
+	// TLSServerName overrides the server name used to verify the server certificate.
+	TLSServerName string `gcfg:"tls-server-name" ini:"tls-server-name" json:"tls_server_name"`
+
+	// KeepaliveTime is the interval of pinging the server on an idle connection.
+	// If zero, keepalive pings are not sent.
+	KeepaliveTime Duration `gcfg:"keepalive-time" ini:"keepalive-time" json:"keepalive_time"`
+
+	// KeepaliveTimeout is the time to wait for a ping acknowledgement
+	// before closing the connection.
+	KeepaliveTimeout Duration `gcfg:"keepalive-timeout" ini:"keepalive-timeout" json:"keepalive_timeout"`
+
+	// KeepalivePermitWithoutStream allows pings when there are no active calls.
+	KeepalivePermitWithoutStream bool `gcfg:"keepalive-permit-without-stream" ini:"keepalive-permit-without-stream" json:"keepalive_permit_without_stream"`
+
+	// Timeout is the default timeout of unary calls that have no deadline.
+	Timeout Duration `gcfg:"timeout" ini:"timeout" json:"timeout"`
+
+	// MaxRecvMsgSize and MaxSendMsgSize limit the size of the received and sent messages
+	// in bytes. If zero, the gRPC defaults are used.
+	MaxRecvMsgSize int `gcfg:"max-recv-msg-size" ini:"max-recv-msg-size" json:"max_recv_msg_size"`
+	MaxSendMsgSize int `gcfg:"max-send-msg-size" ini:"max-send-msg-size" json:"max_send_msg_size"`
+
+	// RetryMaxAttempts enables retries of failed calls of the idempotent methods
+	// if it is greater than one (see [interceptors.RetryPolicy]). The zero values
+	// of other retry parameters are taken from [interceptors.DefaultRetryPolicy].
+	RetryMaxAttempts       int      `gcfg:"retry-max-attempts" ini:"retry-max-attempts" json:"retry_max_attempts"`
+	RetryInitialBackoff    Duration `gcfg:"retry-initial-backoff" ini:"retry-initial-backoff" json:"retry_initial_backoff"`
+	RetryMaxBackoff        Duration `gcfg:"retry-max-backoff" ini:"retry-max-backoff" json:"retry_max_backoff"`
+	RetryPerAttemptTimeout Duration `gcfg:"retry-per-attempt-timeout" ini:"retry-per-attempt-timeout" json:"retry_per_attempt_timeout"`
+
+	// Compression specifies the name of the compressor used for the requests
+	// by default, e.g. "gzip" or "zstd" (see [WithCompression]).
+	Compression string `gcfg:"compression" ini:"compression" json:"compression"`
+
+	// UserAgent specifies a string prepended to the default User-Agent.
+	UserAgent string `gcfg:"user-agent" ini:"user-agent" json:"user_agent"`
+}
+
+// Defaults sets default values for unpopulated fields.
+func (c *Config) Defaults() {
+	if len(c.Targets) == 0 {
+		c.Targets = []string{"127.0.0.1:9191"}
+	}
+
+	if c.KeepaliveTime > 0 && c.KeepaliveTimeout == 0 {
+		c.KeepaliveTimeout = Duration(20 * time.Second)
+	}
+}
+
+// Validate checks that all struct parameters are filled correctly.
+func (c *Config) Validate() error {
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("no one target defined")
+	}
+
+	for _, t := range c.Targets {
+		if utils.HasScheme(t) && len(c.Targets) > 1 {
+			return fmt.Errorf("target with a scheme cannot be combined with other targets: %s", t)
+		}
+	}
+
+	if (len(c.TLSCert) == 0) != (len(c.TLSKey) == 0) {
+		return fmt.Errorf("both TLS certificate and key must be set")
+	}
+
+	if c.KeepaliveTime < 0 || c.KeepaliveTimeout < 0 || c.Timeout < 0 {
+		return fmt.Errorf("durations cannot be negative")
+	}
+
+	if c.MaxRecvMsgSize < 0 || c.MaxSendMsgSize < 0 {
+		return fmt.Errorf("message size limits cannot be negative")
+	}
+
+	if len(c.Compression) > 0 && c.Compression != encoding.Identity {
+		if encoding.GetCompressor(c.Compression) == nil {
+			return fmt.Errorf("unknown compressor: %s", c.Compression)
+		}
+	}
+
+	return nil
+}
+
+// GetTLSConfig returns the TLS configuration built from the TLS fields,
+// or nil if TLS is not enabled.
+func (c *Config) GetTLSConfig() (*tls.Config, error) {
+	if !c.TLS && len(c.TLSCACert) == 0 && len(c.TLSCert) == 0 && len(c.TLSServerName) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: c.TLSServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(c.TLSCACert) > 0 {
+		b, err := os.ReadFile(c.TLSCACert)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no valid certificates found in %s", c.TLSCACert)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(c.TLSCert) > 0 {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// retryPolicy returns the retry policy built from the retry fields.
+func (c *Config) retryPolicy() interceptors.RetryPolicy {
+	p := interceptors.DefaultRetryPolicy()
+
+	p.MaxAttempts = c.RetryMaxAttempts
+
+	if c.RetryInitialBackoff > 0 {
+		p.InitialBackoff = time.Duration(c.RetryInitialBackoff)
+	}
+
+	if c.RetryMaxBackoff > 0 {
+		p.MaxBackoff = time.Duration(c.RetryMaxBackoff)
+	}
+
+	p.PerAttemptTimeout = time.Duration(c.RetryPerAttemptTimeout)
+
+	return p
+}
+
+// DialOptions returns the dial options corresponding to the config fields,
+// except the transport credentials.
+func (c *Config) DialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+
+	if c.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(c.KeepaliveTime),
+			Timeout:             time.Duration(c.KeepaliveTimeout),
+			PermitWithoutStream: c.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	var callOpts []grpc.CallOption
+
+	if c.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(c.MaxRecvMsgSize))
+	}
+
+	if c.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(c.MaxSendMsgSize))
+	}
+
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	if len(c.Compression) > 0 {
+		opts = append(opts, WithCompression(c.Compression))
+	}
+
+	if len(c.UserAgent) > 0 {
+		opts = append(opts, grpc.WithUserAgent(c.UserAgent))
+	}
+
+	// The timeout is set before the retries to limit the total time of the call
+	if c.Timeout > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(interceptors.WithDefaultTimeout(time.Duration(c.Timeout))))
+	}
+
+	if c.RetryMaxAttempts > 1 {
+		policy := c.retryPolicy()
+
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(interceptors.WithRetries(policy, logger)),
+			grpc.WithChainStreamInterceptor(interceptors.WithStreamRetries(policy, logger)),
+		)
+	}
+
+	return opts
+}
+
+// NewConnectionFromConfig returns a gRPC client connection configured according
+// to a given config. The connection is secure if TLS is enabled in the config.
+//
+// Additional dial options can be provided using arguments.
+func NewConnectionFromConfig(cfg *Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := cfg.GetTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConnection(strings.Join(cfg.Targets, ","), tlsConfig, append(cfg.DialOptions(), opts...)...)
+}
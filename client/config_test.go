@@ -0,0 +1,57 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestConfigJSON(t *testing.T) {
+	var cfg Config
+
+	data := `{"targets": ["10.0.0.1", "10.0.0.2:9191"], "timeout": "1.5s", "keepalive_time": "30s", "retry_max_attempts": 3, "compression": "zstd"}`
+
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Defaults()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if time.Duration(cfg.Timeout) != 1500*time.Millisecond {
+		t.Errorf("unexpected timeout: %s", time.Duration(cfg.Timeout))
+	}
+
+	if time.Duration(cfg.KeepaliveTimeout) != 20*time.Second {
+		t.Errorf("unexpected default keepalive timeout: %s", time.Duration(cfg.KeepaliveTimeout))
+	}
+
+	if tlsConfig, err := cfg.GetTLSConfig(); err != nil || tlsConfig != nil {
+		t.Errorf("expected no TLS config, got %v (%v)", tlsConfig, err)
+	}
+
+	conn, err := NewConnectionFromConfig(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Close()
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []Config{
+		{},
+		{Targets: []string{"file:///etc/app/endpoints.json", "10.0.0.1"}},
+		{Targets: []string{"10.0.0.1"}, TLSCert: "client.crt"},
+		{Targets: []string{"10.0.0.1"}, Compression: "lz4"},
+	}
+
+	for _, cfg := range tests {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
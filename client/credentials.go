@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TokenSource is the source of tokens that expire, e.g. OAuth2 access tokens.
+type TokenSource interface {
+	// Token returns a new token and its expiry time. A zero expiry time
+	// means that the token does not expire.
+	Token(ctx context.Context) (token string, expiry time.Time, err error)
+}
+
+// TokenSourceFunc is an adapter to use ordinary functions as [TokenSource].
+type TokenSourceFunc func(ctx context.Context) (string, time.Time, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (string, time.Time, error) {
+	return f(ctx)
+}
+
+var (
+	// TokenFileCheckInterval is the minimal interval between checks of the token file
+	// for changes.
+	TokenFileCheckInterval = time.Second
+
+	// TokenRefreshMargin is the time before the token expiry at which
+	// a new token is requested from the token source.
+	TokenRefreshMargin = 30 * time.Second
+)
+
+// secret is a string that is never printed.
+type secret string
+
+func (secret) String() string   { return "[REDACTED]" }
+func (secret) GoString() string { return "[REDACTED]" }
+
+// TokenCredentials implements [credentials.PerRPCCredentials] by sending a bearer
+// token in the "authorization" metadata of each call.
+//
+// By default, the tokens are sent only over secure connections, the calls
+// over insecure ones fail. Use [TokenCredentials.AllowInsecure] to change this.
+type TokenCredentials struct {
+	get           func(ctx context.Context) (secret, error)
+	allowInsecure bool
+}
+
+// StaticToken returns credentials with a given constant token.
+func StaticToken(token string) *TokenCredentials {
+	return &TokenCredentials{
+		get: func(context.Context) (secret, error) { return secret(token), nil },
+	}
+}
+
+// FileToken returns credentials with a token read from a given file.
+// The file is re-read when it changes, so that the token can be rotated
+// without restarting the process. Leading and trailing white spaces are trimmed.
+func FileToken(filename string) *TokenCredentials {
+	f := &tokenFile{filename: filename}
+
+	return &TokenCredentials{get: f.token}
+}
+
+// SourceToken returns credentials with a token obtained from a given source.
+// A new token is requested when the current one is about to expire
+// (see [TokenRefreshMargin]).
+func SourceToken(src TokenSource) *TokenCredentials {
+	s := &tokenSource{src: src}
+
+	return &TokenCredentials{get: s.token}
+}
+
+// AllowInsecure allows sending the token over insecure connections,
+// e.g. in test environments. It returns the credentials for chaining.
+func (c *TokenCredentials) AllowInsecure() *TokenCredentials {
+	c.allowInsecure = true
+
+	return c
+}
+
+// GetRequestMetadata implements [credentials.PerRPCCredentials].
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"authorization": "Bearer " + string(token)}, nil
+}
+
+// RequireTransportSecurity implements [credentials.PerRPCCredentials].
+// If it returns true, the calls over insecure connections fail without sending the token.
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return !c.allowInsecure
+}
+
+// WithPerRPCCredentials returns a dial option that sets credentials sent with each call,
+// e.g. [StaticToken], [FileToken] or [SourceToken].
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(creds)
+}
+
+type tokenFile struct {
+	mu sync.Mutex
+
+	filename string
+
+	value     secret
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+func (f *tokenFile) token(context.Context) (secret, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.value) > 0 && time.Since(f.checkedAt) < TokenFileCheckInterval {
+		return f.value, nil
+	}
+
+	f.checkedAt = time.Now()
+
+	fi, err := os.Stat(f.filename)
+	if err == nil && len(f.value) > 0 && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return f.value, nil
+	}
+
+	if err == nil {
+		var token secret
+
+		if token, err = readTokenFile(f.filename); err == nil {
+			f.value, f.modTime, f.size = token, fi.ModTime(), fi.Size()
+
+			logger.WithField("filename", f.filename).Debug("Token loaded")
+
+			return f.value, nil
+		}
+	}
+
+	if len(f.value) > 0 {
+		// Keep using the previous token
+		logger.WithField("filename", f.filename).WithError(err).Warn("Cannot reload token")
+
+		return f.value, nil
+	}
+
+	return "", err
+}
+
+func readTokenFile(filename string) (secret, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(b))
+
+	if len(token) == 0 {
+		return "", fmt.Errorf("token file is empty: %s", filename)
+	}
+
+	return secret(token), nil
+}
+
+type tokenSource struct {
+	mu sync.Mutex
+
+	src TokenSource
+
+	value  secret
+	expiry time.Time
+}
+
+func (s *tokenSource) token(ctx context.Context) (secret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if len(s.value) > 0 && (s.expiry.IsZero() || now.Before(s.expiry.Add(-TokenRefreshMargin))) {
+		return s.value, nil
+	}
+
+	token, expiry, err := s.src.Token(ctx)
+	if err == nil && len(token) == 0 {
+		err = errors.New("token source returned empty token")
+	}
+
+	if err != nil {
+		if len(s.value) > 0 && now.Before(s.expiry) {
+			// The current token is still valid
+			logger.WithError(err).Warn("Cannot refresh token")
+
+			return s.value, nil
+		}
+
+		return "", fmt.Errorf("cannot obtain token: %w", err)
+	}
+
+	s.value, s.expiry = secret(token), expiry
+
+	return s.value, nil
+}
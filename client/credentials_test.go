@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestFileToken(t *testing.T) {
+	defer func(v time.Duration) { TokenFileCheckInterval = v }(TokenFileCheckInterval)
+
+	TokenFileCheckInterval = 0
+
+	filename := filepath.Join(t.TempDir(), "token")
+
+	os.WriteFile(filename, []byte("first\n"), 0600)
+
+	creds := FileToken(filename)
+
+	if md, err := creds.GetRequestMetadata(context.Background()); err != nil || md["authorization"] != "Bearer first" {
+		t.Fatalf("unexpected metadata: %v (%v)", md, err)
+	}
+
+	os.WriteFile(filename, []byte("second-token"), 0600)
+
+	if md, _ := creds.GetRequestMetadata(context.Background()); md["authorization"] != "Bearer second-token" {
+		t.Fatalf("token was not reloaded: %v", md)
+	}
+
+	// The previous token is used if the file is broken
+	os.WriteFile(filename, nil, 0600)
+
+	if md, _ := creds.GetRequestMetadata(context.Background()); md["authorization"] != "Bearer second-token" {
+		t.Fatalf("unexpected metadata: %v", md)
+	}
+}
+
+func TestSourceToken(t *testing.T) {
+	defer func(v time.Duration) { TokenRefreshMargin = v }(TokenRefreshMargin)
+
+	calls := 0
+
+	creds := SourceToken(TokenSourceFunc(func(context.Context) (string, time.Time, error) {
+		calls++
+
+		// The first token expires within the refresh margin
+		return fmt.Sprintf("token-%d", calls), time.Now().Add(time.Duration(calls) * time.Hour / 2), nil
+	}))
+
+	for _, want := range []string{"Bearer token-1", "Bearer token-2", "Bearer token-2"} {
+		md, err := creds.GetRequestMetadata(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if md["authorization"] != want {
+			t.Fatalf("unexpected metadata: got %v, want %s", md, want)
+		}
+
+		// Force the refresh of the first token only
+		if calls == 1 {
+			TokenRefreshMargin = time.Hour
+		} else {
+			TokenRefreshMargin = time.Minute
+		}
+	}
+}
+
+func TestTokenInsecure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+
+	grpc_health.RegisterHealthServer(srv, health.NewServer())
+
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	check := func(creds *TokenCredentials) error {
+		conn, err := NewInsecureConnection(l.Addr().String(), WithPerRPCCredentials(creds))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		_, err = grpc_health.NewHealthClient(conn).Check(context.Background(), &grpc_health.HealthCheckRequest{})
+
+		return err
+	}
+
+	if err := check(StaticToken("secret")); err == nil {
+		t.Fatal("expected an error for insecure connection")
+	}
+
+	if err := check(StaticToken("secret").AllowInsecure()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTokenNotPrinted(t *testing.T) {
+	s := fmt.Sprintf("%v %+v %#v", secret("secret"), *StaticToken("secret"), secret("secret"))
+
+	if strings.Contains(s, "secret") {
+		t.Fatalf("token is printed: %s", s)
+	}
+}
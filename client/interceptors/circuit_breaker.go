@@ -0,0 +1,413 @@
+package interceptors
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/status"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+// CircuitState is a state of the circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed means that calls are passed through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen means that calls fail fast with the Unavailable code.
+	CircuitOpen
+
+	// CircuitHalfOpen means that a limited number of trial calls is passed through
+	// to check whether the dependency has recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s CircuitState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// CircuitBreakerPolicy describes when the circuit breaker opens and closes.
+type CircuitBreakerPolicy struct {
+	// FailureRate is the share of failed calls in the range (0, 1]
+	// within the window at which the circuit opens.
+	FailureRate float64
+
+	// MinRequests is the minimum number of calls within the window
+	// required to evaluate the failure rate.
+	MinRequests int
+
+	// Window is the length of the sliding window over which the failure rate
+	// is calculated.
+	Window time.Duration
+
+	// OpenTimeout is the time the circuit stays open before switching
+	// to the half-open state.
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of trial calls allowed in the half-open state.
+	// The circuit closes if all of them succeed and opens again if any of them fails.
+	HalfOpenRequests int
+
+	// FailureCodes is the set of codes counted as failures.
+	FailureCodes []grpc_codes.Code
+}
+
+// DefaultCircuitBreakerPolicy returns a policy with reasonable default values.
+func DefaultCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return CircuitBreakerPolicy{
+		FailureRate:      0.5,
+		MinRequests:      20,
+		Window:           30 * time.Second,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenRequests: 3,
+		FailureCodes: []grpc_codes.Code{
+			grpc_codes.Unavailable,
+			grpc_codes.DeadlineExceeded,
+		},
+	}
+}
+
+// circuitBuckets is the number of buckets of the sliding window.
+const circuitBuckets = 10
+
+// CircuitInfo describes the current state of a single circuit.
+type CircuitInfo struct {
+	Target   string       `json:"target"`
+	Method   string       `json:"method"`
+	State    CircuitState `json:"state"`
+	Requests int          `json:"requests"`
+	Failures int          `json:"failures"`
+	Since    time.Time    `json:"since"`
+}
+
+// CircuitBreaker tracks the calls per target and method and stops calling
+// the failing ones (see [WithCircuitBreaker]).
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	policy   CircuitBreakerPolicy
+	logger   logging.Logger
+	circuits map[circuitKey]*circuit
+}
+
+type circuitKey struct {
+	target string
+	method string
+}
+
+type circuitBucket struct {
+	start    time.Time
+	requests int
+	failures int
+}
+
+type circuit struct {
+	state CircuitState
+	since time.Time
+
+	buckets [circuitBuckets]circuitBucket
+
+	// Trial calls in the half-open state
+	probes    int
+	successes int
+}
+
+// NewCircuitBreaker returns a new circuit breaker with a given policy.
+// The state changes are logged using a given logger.
+//
+// Unset or invalid fields of the policy are replaced with the values
+// from [DefaultCircuitBreakerPolicy].
+func NewCircuitBreaker(policy CircuitBreakerPolicy, logger logging.Logger) *CircuitBreaker {
+	defaults := DefaultCircuitBreakerPolicy()
+
+	if policy.FailureRate <= 0 || policy.FailureRate > 1 {
+		policy.FailureRate = defaults.FailureRate
+	}
+
+	if policy.MinRequests < 1 {
+		policy.MinRequests = defaults.MinRequests
+	}
+
+	// Each bucket of the window must be at least 1ns long
+	if policy.Window < circuitBuckets {
+		policy.Window = defaults.Window
+	}
+
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = defaults.OpenTimeout
+	}
+
+	if policy.HalfOpenRequests < 1 {
+		policy.HalfOpenRequests = 1
+	}
+
+	if len(policy.FailureCodes) == 0 {
+		policy.FailureCodes = defaults.FailureCodes
+	}
+
+	return &CircuitBreaker{
+		policy:   policy,
+		logger:   logger,
+		circuits: make(map[circuitKey]*circuit),
+	}
+}
+
+// State returns the current state of the circuit for a given target
+// and full method name.
+func (b *CircuitBreaker) State(target, fullMethod string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[circuitKey{target, fullMethod}]; ok {
+		return c.state
+	}
+
+	return CircuitClosed
+}
+
+// Circuits returns the states of all known circuits sorted by target and method.
+func (b *CircuitBreaker) Circuits() []CircuitInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	infos := make([]CircuitInfo, 0, len(b.circuits))
+
+	for key, c := range b.circuits {
+		requests, failures := c.counts(now, b.policy.Window)
+
+		infos = append(infos, CircuitInfo{
+			Target:   key.target,
+			Method:   key.method,
+			State:    c.state,
+			Requests: requests,
+			Failures: failures,
+			Since:    c.since,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Target != infos[j].Target {
+			return infos[i].Target < infos[j].Target
+		}
+
+		return infos[i].Method < infos[j].Method
+	})
+
+	return infos
+}
+
+// allow reports whether a call can be performed. Otherwise, it returns the time
+// remaining until the circuit switches to the half-open state.
+func (b *CircuitBreaker) allow(key circuitKey, logger logging.Logger) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{since: time.Now()}
+
+		b.circuits[key] = c
+	}
+
+	now := time.Now()
+
+	switch c.state {
+	case CircuitOpen:
+		if remaining := c.since.Add(b.policy.OpenTimeout).Sub(now); remaining > 0 {
+			return false, remaining
+		}
+
+		b.setState(c, CircuitHalfOpen, logger)
+
+		fallthrough
+	case CircuitHalfOpen:
+		// Trial calls that have never completed (e.g. abandoned streams)
+		// should not keep the circuit half-open forever
+		if now.Sub(c.since) > b.policy.OpenTimeout {
+			c.since, c.probes, c.successes = now, 0, 0
+		}
+
+		if c.probes >= b.policy.HalfOpenRequests {
+			return false, b.policy.OpenTimeout - now.Sub(c.since)
+		}
+
+		c.probes++
+	}
+
+	return true, 0
+}
+
+// record accounts the result of a call.
+func (b *CircuitBreaker) record(key circuitKey, err error, logger logging.Logger) {
+	failed := err != nil && slices.Contains(b.policy.FailureCodes, grpc_status.Code(err))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+
+	switch c.state {
+	case CircuitClosed:
+		c.add(now, b.policy.Window, failed)
+
+		if !failed {
+			return
+		}
+
+		requests, failures := c.counts(now, b.policy.Window)
+
+		if requests >= b.policy.MinRequests && float64(failures) >= b.policy.FailureRate*float64(requests) {
+			b.setState(c, CircuitOpen, logger.WithError(err))
+		}
+	case CircuitHalfOpen:
+		if failed {
+			b.setState(c, CircuitOpen, logger.WithError(err))
+
+			return
+		}
+
+		if c.successes++; c.successes >= b.policy.HalfOpenRequests {
+			b.setState(c, CircuitClosed, logger)
+		}
+	}
+}
+
+func (b *CircuitBreaker) setState(c *circuit, state CircuitState, logger logging.Logger) {
+	logger.WithFields(logging.Fields{"circuit.prev_state": c.state.String(), "circuit.state": state.String()}).Warn("Circuit breaker state changed")
+
+	c.state = state
+	c.since = time.Now()
+	c.probes, c.successes = 0, 0
+
+	if state == CircuitClosed {
+		c.buckets = [circuitBuckets]circuitBucket{}
+	}
+}
+
+// add accounts a call in the current bucket of the sliding window.
+func (c *circuit) add(now time.Time, window time.Duration, failed bool) {
+	size := window / circuitBuckets
+
+	start := now.Truncate(size)
+
+	bucket := &c.buckets[(start.UnixNano()/int64(size))%circuitBuckets]
+
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+
+	bucket.requests++
+
+	if failed {
+		bucket.failures++
+	}
+}
+
+// counts returns the number of calls and failures within the sliding window.
+func (c *circuit) counts(now time.Time, window time.Duration) (requests, failures int) {
+	for _, bucket := range c.buckets {
+		if now.Sub(bucket.start) < window {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+
+	return requests, failures
+}
+
+func (b *CircuitBreaker) prepare(ctx context.Context, cc *grpc.ClientConn, method string) (circuitKey, logging.Logger, error) {
+	key := circuitKey{method: method}
+
+	if cc != nil {
+		key.target = cc.Target()
+	}
+
+	logger := b.logger.WithFields(logging.Fields{"request.uid": requestIDForLog(ctx), "grpc.method": method, "server": key.target})
+
+	if ok, remaining := b.allow(key, logger); !ok {
+		return key, logger, status.Error(ctx, grpc_codes.Unavailable, "circuit breaker is open", status.RetryInfo(remaining))
+	}
+
+	return key, logger, nil
+}
+
+// WithCircuitBreaker returns an unary client interceptor that fails fast
+// with the Unavailable code when the circuit of the called target and method
+// is open.
+func WithCircuitBreaker(b *CircuitBreaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		key, logger, err := b.prepare(ctx, cc, method)
+		if err != nil {
+			return err
+		}
+
+		err = invoker(ctx, method, req, reply, cc, opts...)
+
+		b.record(key, err, logger)
+
+		return err
+	}
+}
+
+// WithStreamCircuitBreaker returns a stream client interceptor that fails fast
+// with the Unavailable code when the circuit of the called target and method
+// is open.
+//
+// The result of a stream is accounted when the stream finishes (see [grpc.OnFinish]),
+// so client-streaming calls and streams that are not read till the end are also counted.
+func WithStreamCircuitBreaker(b *CircuitBreaker) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		key, logger, err := b.prepare(ctx, cc, method)
+		if err != nil {
+			return nil, err
+		}
+
+		var once sync.Once
+
+		done := func(err error) {
+			once.Do(func() {
+				b.record(key, err, logger)
+			})
+		}
+
+		opts = append(opts[:len(opts):len(opts)], grpc.OnFinish(done))
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			done(err)
+
+			return nil, err
+		}
+
+		return cs, nil
+	}
+}
@@ -0,0 +1,164 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	policy := DefaultCircuitBreakerPolicy()
+
+	policy.MinRequests = 4
+	policy.OpenTimeout = 50 * time.Millisecond
+	policy.HalfOpenRequests = 2
+
+	b := NewCircuitBreaker(policy, logging.Discard)
+
+	interceptor := WithCircuitBreaker(b)
+
+	var fail bool
+	var calls int
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+
+		if fail {
+			return grpc_status.Error(grpc_codes.Unavailable, "unavailable")
+		}
+
+		return nil
+	}
+
+	call := func() error {
+		return interceptor(context.Background(), "/test.Svc/Get", nil, nil, nil, invoker)
+	}
+
+	// Successful calls keep the circuit closed
+	call()
+	call()
+
+	fail = true
+
+	call()
+	call()
+
+	if s := b.State("", "/test.Svc/Get"); s != CircuitOpen {
+		t.Fatalf("expected the open state, got %s", s)
+	}
+
+	calls = 0
+
+	if err := call(); grpc_status.Code(err) != grpc_codes.Unavailable || calls != 0 {
+		t.Fatalf("expected a fast failure, got %v (calls = %d)", err, calls)
+	}
+
+	// Other methods are not affected
+	if s := b.State("", "/test.Svc/List"); s != CircuitClosed {
+		t.Fatalf("expected the closed state for another method, got %s", s)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// The failed trial call opens the circuit again
+	call()
+
+	if s := b.State("", "/test.Svc/Get"); s != CircuitOpen {
+		t.Fatalf("expected the open state after the failed trial, got %s", s)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	fail = false
+
+	call()
+
+	if s := b.State("", "/test.Svc/Get"); s != CircuitHalfOpen {
+		t.Fatalf("expected the half-open state, got %s", s)
+	}
+
+	call()
+
+	if s := b.State("", "/test.Svc/Get"); s != CircuitClosed {
+		t.Fatalf("expected the closed state after the successful trials, got %s", s)
+	}
+
+	if infos := b.Circuits(); len(infos) != 1 || infos[0].Method != "/test.Svc/Get" {
+		t.Fatalf("unexpected circuits: %+v", infos)
+	}
+}
+
+func TestCircuitBreakerPolicyDefaults(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerPolicy{FailureRate: 2, Window: 5}, logging.Discard)
+
+	defaults := DefaultCircuitBreakerPolicy()
+
+	if b.policy.FailureRate != defaults.FailureRate || b.policy.MinRequests != defaults.MinRequests || b.policy.Window != defaults.Window || b.policy.OpenTimeout != defaults.OpenTimeout || len(b.policy.FailureCodes) == 0 {
+		t.Fatalf("got invalid policy: %+v", b.policy)
+	}
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return grpc_status.Error(grpc_codes.Unavailable, "unavailable")
+	}
+
+	// Must not panic with the zero window
+	if err := WithCircuitBreaker(NewCircuitBreaker(CircuitBreakerPolicy{}, logging.Discard))(context.Background(), "/test.Svc/Get", nil, nil, nil, invoker); grpc_status.Code(err) != grpc_codes.Unavailable {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+}
+
+func TestStreamCircuitBreakerOnFinish(t *testing.T) {
+	policy := DefaultCircuitBreakerPolicy()
+
+	policy.MinRequests = 2
+	policy.OpenTimeout = 50 * time.Millisecond
+	policy.HalfOpenRequests = 1
+
+	b := NewCircuitBreaker(policy, logging.Discard)
+
+	var finish func(error)
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		for _, o := range opts {
+			if v, ok := o.(grpc.OnFinishCallOption); ok {
+				finish = v.OnFinish
+			}
+		}
+
+		return &fakeClientStream{}, nil
+	}
+
+	interceptor := WithStreamCircuitBreaker(b)
+
+	// The streams are never read, the results come from the finish callback
+	for i := 0; i < 2; i++ {
+		if _, err := interceptor(context.Background(), &grpc.StreamDesc{ClientStreams: true}, nil, "/test.Svc/Upload", streamer); err != nil {
+			t.Fatal(err)
+		}
+
+		finish(grpc_status.Error(grpc_codes.Unavailable, "unavailable"))
+	}
+
+	if s := b.State("", "/test.Svc/Upload"); s != CircuitOpen {
+		t.Fatalf("expected the open state, got %s", s)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// The trial stream must release its slot when it finishes
+	if _, err := interceptor(context.Background(), &grpc.StreamDesc{ClientStreams: true}, nil, "/test.Svc/Upload", streamer); err != nil {
+		t.Fatal(err)
+	}
+
+	finish(nil)
+
+	if s := b.State("", "/test.Svc/Upload"); s != CircuitClosed {
+		t.Fatalf("expected the closed state after the successful trial, got %s", s)
+	}
+}
@@ -0,0 +1,73 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/0xef53/go-grpc/proto/method"
+
+	"google.golang.org/grpc"
+)
+
+// DefaultCompressorCallOption is a call option that sets the compressor
+// used for the requests if neither the method option of type [options.MethodPolicy]
+// nor the [grpc.UseCompressor] call option select one.
+//
+// It is handled by the compression interceptors and has no effect without them.
+type DefaultCompressorCallOption struct {
+	grpc.EmptyCallOption
+
+	Name string
+}
+
+// UseDefaultCompressor returns a call option that sets the default compressor
+// for the requests. It is intended to be used with [grpc.WithDefaultCallOptions].
+func UseDefaultCompressor(name string) grpc.CallOption {
+	return DefaultCompressorCallOption{Name: name}
+}
+
+// WithCompression returns an unary client interceptor that selects the compressor
+// for the outgoing request.
+//
+// The compressor is chosen in the following order: the [grpc.UseCompressor] call option,
+// the method option of type [options.MethodPolicy], the [UseDefaultCompressor] call option.
+func WithCompression() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(ctx, method, req, reply, cc, withCompressor(method, opts)...)
+	}
+}
+
+// WithStreamCompression returns a stream client interceptor that selects the compressor
+// for the outgoing request.
+//
+// The compressor is chosen in the same way as in [WithCompression].
+func WithStreamCompression() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(ctx, desc, cc, method, withCompressor(method, opts)...)
+	}
+}
+
+func withCompressor(fullMethod string, opts []grpc.CallOption) []grpc.CallOption {
+	var defaultName string
+
+	for _, o := range opts {
+		switch v := o.(type) {
+		case grpc.CompressorCallOption:
+			// Explicitly set for the call
+			return opts
+		case DefaultCompressorCallOption:
+			defaultName = v.Name
+		}
+	}
+
+	name := method.Policy(fullMethod).GetCompression()
+
+	if len(name) == 0 {
+		name = defaultName
+	}
+
+	if len(name) == 0 {
+		return opts
+	}
+
+	return append(opts[:len(opts):len(opts)], grpc.UseCompressor(name))
+}
@@ -0,0 +1,360 @@
+package interceptors
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/proto/method"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_peer "google.golang.org/grpc/peer"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// HedgingPolicy describes how hedged requests are sent.
+type HedgingPolicy struct {
+	// MaxAttempts is the maximum number of copies of the request including
+	// the original one. Values less than 2 disable hedging.
+	MaxAttempts int
+
+	// Delay is the time after which the next copy is sent if no response
+	// has been received yet.
+	Delay time.Duration
+
+	// Percentile, if not zero, is the percentile (in the range 1-99) of the recent
+	// latencies of the method that is used as the delay instead of the fixed value.
+	// The fixed value is used until enough latencies are collected.
+	Percentile int
+
+	// Idempotent reports whether a given method can be safely executed several times.
+	// By default, the standard "idempotency_level" method option is used
+	// (see [method.Idempotent]). Calls of non-idempotent methods are not hedged.
+	Idempotent func(fullMethod string) bool
+}
+
+func (p *HedgingPolicy) idempotent(fullMethod string) bool {
+	if p.Idempotent != nil {
+		return p.Idempotent(fullMethod)
+	}
+
+	return method.Idempotent(fullMethod)
+}
+
+// HedgingCallOption is a call option that enables hedging for the call.
+//
+// It is handled by the hedging interceptor and has no effect without it.
+type HedgingCallOption struct {
+	grpc.EmptyCallOption
+
+	Policy HedgingPolicy
+}
+
+// UseHedging returns a call option that enables hedging with a given policy.
+// It takes precedence over the method option of type [options.MethodPolicy].
+func UseHedging(policy HedgingPolicy) grpc.CallOption {
+	return HedgingCallOption{Policy: policy}
+}
+
+const (
+	// The number of recent latencies kept for each method
+	hedgingSamples = 100
+
+	// The minimum number of latencies required to calculate a percentile
+	hedgingMinSamples = 20
+)
+
+// WithHedging returns an unary client interceptor that sends additional copies
+// of the request if no response is received within the delay. The first successful
+// response is returned, and the remaining calls are canceled.
+//
+// Methods opt in using the method option of type [options.MethodPolicy] or
+// the [UseHedging] call option. Since the request may be executed several times,
+// only idempotent methods are hedged (see [HedgingPolicy.Idempotent]), the calls
+// of the other ones are sent once and a warning is logged.
+//
+// The [grpc.Header], [grpc.Trailer] and [grpc.Peer] call options receive
+// the values of the call whose result is returned.
+//
+// Each copy gets a request ID derived from the ID of the original request
+// (see [WithRequestIdentifier]). An error with the Unavailable code causes
+// the next copy to be sent immediately, any other error is returned as is.
+func WithHedging(logger logging.Logger) grpc.UnaryClientInterceptor {
+	h := &hedger{
+		latencies: make(map[string][]time.Duration),
+		warned:    make(map[string]struct{}),
+	}
+
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := hedgingPolicy(method, opts)
+
+		replyMsg, ok := reply.(proto.Message)
+
+		if policy.MaxAttempts < 2 || !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		logger := logger.WithFields(logging.Fields{"request.uid": requestIDForLog(ctx), "grpc.method": method})
+
+		if !policy.idempotent(method) {
+			if h.warnOnce(method) {
+				logger.Warn("Hedging is configured for a non-idempotent method, the calls are not hedged")
+			}
+
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		return h.invoke(ctx, logger, &policy, method, req, replyMsg, cc, invoker, opts...)
+	}
+}
+
+func hedgingPolicy(fullMethod string, opts []grpc.CallOption) HedgingPolicy {
+	for _, o := range opts {
+		if v, ok := o.(HedgingCallOption); ok {
+			return v.Policy
+		}
+	}
+
+	p := method.Policy(fullMethod).GetHedging()
+
+	return HedgingPolicy{
+		MaxAttempts: int(p.GetMaxAttempts()),
+		Delay:       time.Duration(p.GetDelayMs()) * time.Millisecond,
+		Percentile:  int(p.GetDelayPercentile()),
+	}
+}
+
+// hedger keeps the recent latencies of the hedged methods.
+type hedger struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+
+	// Non-idempotent methods that have been reported
+	warned map[string]struct{}
+}
+
+// warnOnce reports whether a given method has not been reported yet.
+func (h *hedger) warnOnce(method string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.warned[method]; ok {
+		return false
+	}
+
+	h.warned[method] = struct{}{}
+
+	return true
+}
+
+func (h *hedger) observe(method string, d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	l := append(h.latencies[method], d)
+
+	if len(l) > hedgingSamples {
+		l = l[len(l)-hedgingSamples:]
+	}
+
+	h.latencies[method] = l
+}
+
+// delay returns the delay before sending the next copy.
+func (h *hedger) delay(method string, policy *HedgingPolicy) time.Duration {
+	if policy.Percentile <= 0 || policy.Percentile >= 100 {
+		return policy.Delay
+	}
+
+	h.mu.Lock()
+
+	l := slices.Clone(h.latencies[method])
+
+	h.mu.Unlock()
+
+	if len(l) < hedgingMinSamples {
+		return policy.Delay
+	}
+
+	slices.Sort(l)
+
+	return l[len(l)*policy.Percentile/100]
+}
+
+type hedgingResult struct {
+	reply   proto.Message
+	err     error
+	attempt int
+	outputs *callOutputs
+}
+
+// callOutputs holds the values written by the [grpc.Header], [grpc.Trailer]
+// and [grpc.Peer] call options. Each copy of the request gets its own instance,
+// so that the concurrent calls do not write to the same variables.
+type callOutputs struct {
+	header  grpc_metadata.MD
+	trailer grpc_metadata.MD
+	peer    grpc_peer.Peer
+}
+
+// outputOptions separates the output call options from the others.
+type outputOptions struct {
+	headers  []*grpc_metadata.MD
+	trailers []*grpc_metadata.MD
+	peers    []*grpc_peer.Peer
+}
+
+func splitOutputOptions(opts []grpc.CallOption) ([]grpc.CallOption, *outputOptions) {
+	out := new(outputOptions)
+
+	rest := make([]grpc.CallOption, 0, len(opts))
+
+	for _, o := range opts {
+		switch v := o.(type) {
+		case grpc.HeaderCallOption:
+			out.headers = append(out.headers, v.HeaderAddr)
+		case grpc.TrailerCallOption:
+			out.trailers = append(out.trailers, v.TrailerAddr)
+		case grpc.PeerCallOption:
+			out.peers = append(out.peers, v.PeerAddr)
+		default:
+			rest = append(rest, o)
+		}
+	}
+
+	return rest, out
+}
+
+// callOptions returns the output call options writing to a given instance.
+func (o *outputOptions) callOptions(v *callOutputs) []grpc.CallOption {
+	var opts []grpc.CallOption
+
+	if len(o.headers) > 0 {
+		opts = append(opts, grpc.Header(&v.header))
+	}
+
+	if len(o.trailers) > 0 {
+		opts = append(opts, grpc.Trailer(&v.trailer))
+	}
+
+	if len(o.peers) > 0 {
+		opts = append(opts, grpc.Peer(&v.peer))
+	}
+
+	return opts
+}
+
+// apply copies the values of the finished call to the caller's variables.
+func (o *outputOptions) apply(v *callOutputs) {
+	for _, md := range o.headers {
+		*md = v.header
+	}
+
+	for _, md := range o.trailers {
+		*md = v.trailer
+	}
+
+	for _, p := range o.peers {
+		*p = v.peer
+	}
+}
+
+func (h *hedger) invoke(ctx context.Context, logger logging.Logger, policy *HedgingPolicy, method string, req interface{}, reply proto.Message, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered to not block the remaining calls after return
+	results := make(chan hedgingResult, policy.MaxAttempts)
+
+	opts, outputs := splitOutputOptions(opts)
+
+	send := func(attempt int) {
+		ctx := ctx
+
+		if attempt > 1 {
+			ctx = withRequestID(ctx)
+
+			logger.WithFields(logging.Fields{"attempt": attempt, "request.hedge_uid": requestIDForLog(ctx)}).Debug("Sending hedged request")
+		}
+
+		// Each call gets its own reply message and output values
+		r := reply.ProtoReflect().New().Interface()
+
+		v := new(callOutputs)
+
+		opts := append(opts[:len(opts):len(opts)], outputs.callOptions(v)...)
+
+		go func() {
+			results <- hedgingResult{r, invoker(ctx, method, req, r, cc, opts...), attempt, v}
+		}()
+	}
+
+	delay := h.delay(method, policy)
+	start := time.Now()
+
+	send(1)
+
+	sent, failed := 1, 0
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if sent < policy.MaxAttempts {
+				sent++
+
+				send(sent)
+
+				timer.Reset(delay)
+			}
+		case res := <-results:
+			if res.err == nil {
+				h.observe(method, time.Since(start))
+
+				proto.Reset(reply)
+				proto.Merge(reply, res.reply)
+
+				if res.attempt > 1 {
+					logger.WithField("attempt", res.attempt).Debug("Hedged request won")
+				}
+
+				outputs.apply(res.outputs)
+
+				return nil
+			}
+
+			if grpc_status.Code(res.err) != grpc_codes.Unavailable {
+				outputs.apply(res.outputs)
+
+				return res.err
+			}
+
+			failed++
+
+			if failed < sent {
+				// Wait for the calls in flight
+				continue
+			}
+
+			if sent >= policy.MaxAttempts {
+				outputs.apply(res.outputs)
+
+				return res.err
+			}
+
+			// All calls have failed, send the next copy right away
+			sent++
+
+			send(sent)
+
+			timer.Reset(delay)
+		}
+	}
+}
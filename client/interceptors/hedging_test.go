@@ -0,0 +1,127 @@
+package interceptors
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/requestid"
+
+	"google.golang.org/grpc"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWithHedging(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+
+	canceled := make(chan struct{})
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := grpc_metadata.FromOutgoingContext(ctx)
+
+		mu.Lock()
+		ids = append(ids, md.Get(requestid.MetadataKey())[0])
+		attempt := len(ids)
+		mu.Unlock()
+
+		// Each copy writes its own header
+		for _, o := range opts {
+			if v, ok := o.(grpc.HeaderCallOption); ok {
+				*v.HeaderAddr = grpc_metadata.Pairs("attempt", strconv.Itoa(attempt))
+			}
+		}
+
+		if attempt == 1 {
+			// The original request hangs until it is canceled
+			<-ctx.Done()
+
+			close(canceled)
+
+			return ctx.Err()
+		}
+
+		reply.(*wrapperspb.StringValue).Value = "hedged"
+
+		return nil
+	}
+
+	ctx := withRequestID(context.Background())
+
+	reply := new(wrapperspb.StringValue)
+
+	var header grpc_metadata.MD
+
+	policy := HedgingPolicy{
+		MaxAttempts: 3,
+		Delay:       10 * time.Millisecond,
+		Idempotent:  func(string) bool { return true },
+	}
+
+	err := WithHedging(logging.Discard)(ctx, "/test.Svc/Get", nil, reply, nil, invoker, UseHedging(policy), grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reply.Value != "hedged" {
+		t.Fatalf("unexpected reply: %q", reply.Value)
+	}
+
+	if v := header.Get("attempt"); len(v) != 1 || v[0] != "2" {
+		t.Fatalf("got invalid header: want the one of the winner, got %v", header)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the original request was not canceled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(ids))
+	}
+
+	if ids[0] == ids[1] || requestid.Parent(ids[1]) != ids[0] {
+		t.Fatalf("the hedged request ID is not derived from the original one: %v", ids)
+	}
+}
+
+func TestWithHedgingDisabled(t *testing.T) {
+	calls := 0
+
+	err := WithHedging(logging.Discard)(context.Background(), "/test.Svc/Get", nil, new(wrapperspb.StringValue), nil, failingInvoker(&calls))
+	if err != nil || calls != 1 {
+		t.Fatalf("unexpected result: err = %v, calls = %d", err, calls)
+	}
+}
+
+func TestWithHedgingNonIdempotent(t *testing.T) {
+	calls := 0
+
+	policy := HedgingPolicy{
+		MaxAttempts: 3,
+		Delay:       time.Millisecond,
+		Idempotent:  func(string) bool { return false },
+	}
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+
+		// Long enough for the copies to be sent
+		time.Sleep(20 * time.Millisecond)
+
+		return nil
+	}
+
+	err := WithHedging(logging.Discard)(context.Background(), "/test.Svc/Create", nil, new(wrapperspb.StringValue), nil, invoker, UseHedging(policy))
+	if err != nil || calls != 1 {
+		t.Fatalf("unexpected result: err = %v, calls = %d", err, calls)
+	}
+}
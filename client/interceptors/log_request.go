@@ -2,13 +2,20 @@ package interceptors
 
 import (
 	"context"
+	"io"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/0xef53/go-grpc/logging"
 	"github.com/0xef53/go-grpc/proto/message"
+	"github.com/0xef53/go-grpc/requestid"
 
 	"google.golang.org/grpc"
 	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_status "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	log "github.com/sirupsen/logrus"
@@ -18,6 +25,12 @@ import (
 // start/end time, target server, full method name, request metadata, fields allowed to be displayed
 // (see github.com/0xef53/go-grpc/options) and errors.
 func WithRequestLogging(logger *log.Entry) grpc.UnaryClientInterceptor {
+	return WithRequestLoggingAdapter(logging.NewLogrusLogger(logger))
+}
+
+// WithRequestLoggingAdapter is like [WithRequestLogging] but accepts any [logging.Logger]
+// implementation, e.g. the one returned by [logging.NewSlogLogger].
+func WithRequestLoggingAdapter(logger logging.Logger) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		start := time.Now()
 
@@ -43,10 +56,40 @@ func WithRequestLogging(logger *log.Entry) grpc.UnaryClientInterceptor {
 	}
 }
 
+// StreamLoggingOption is a common type for optional parameters of [WithStreamRequestLogging]
+// and [WithStreamRequestLoggingAdapter].
+type StreamLoggingOption func(*streamLoggingOptions)
+
+type streamLoggingOptions struct {
+	logMessages bool
+}
+
+// WithStreamMessages enables logging of the tags of each message sent or received over the stream.
+func WithStreamMessages() StreamLoggingOption {
+	return func(o *streamLoggingOptions) {
+		o.logMessages = true
+	}
+}
+
 // WithStreamRequestLogging returns a stream client interceptor that logs details about the request and response:
 // start/end time, target server, full method name, request metadata, fields allowed to be displayed
 // (see github.com/0xef53/go-grpc/options) and errors.
-func WithStreamRequestLogging(logger *log.Entry) grpc.StreamClientInterceptor {
+//
+// The returned stream counts sent and received messages and bytes. Once the stream ends,
+// a completion line with the final status, duration and counters is logged.
+func WithStreamRequestLogging(logger *log.Entry, opts ...StreamLoggingOption) grpc.StreamClientInterceptor {
+	return WithStreamRequestLoggingAdapter(logging.NewLogrusLogger(logger), opts...)
+}
+
+// WithStreamRequestLoggingAdapter is like [WithStreamRequestLogging] but accepts any [logging.Logger]
+// implementation, e.g. the one returned by [logging.NewSlogLogger].
+func WithStreamRequestLoggingAdapter(logger logging.Logger, opts ...StreamLoggingOption) grpc.StreamClientInterceptor {
+	o := new(streamLoggingOptions)
+
+	for _, fn := range opts {
+		fn(o)
+	}
+
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		start := time.Now()
 
@@ -57,28 +100,144 @@ func WithStreamRequestLogging(logger *log.Entry) grpc.StreamClientInterceptor {
 
 		logger.WithFields(fields).WithField("duration", time.Since(start)).Info("Invoked RPC stream")
 
+		s := &accountedClientStream{
+			logger:        logger.WithFields(fields),
+			logMessages:   o.logMessages,
+			serverStreams: desc.ServerStreams,
+			start:         start,
+		}
+
+		// The completion is also logged if the stream is abandoned or canceled
+		// through the context without being drained
+		opts = append(opts[:len(opts):len(opts)], grpc.OnFinish(s.finish))
+
 		// Call the streamer
 		stream, err := streamer(ctx, desc, cc, method, opts...)
 		if err != nil {
-			logger.WithError(err).WithField("duration", time.Since(start)).Error("Failed RPC stream")
+			s.finish(err)
 
 			return nil, err
 		}
 
-		logger.WithField("duration", time.Since(start)).Info("Completed RPC stream")
+		s.ClientStream = stream
 
-		return stream, nil
+		return s, nil
 	}
 }
 
-func propertiesAsFields(ctx context.Context, req, reply interface{}) log.Fields {
-	fields := make(log.Fields)
+// accountedClientStream wraps [grpc.ClientStream] to count sent and received
+// messages and bytes, and to log the stream completion exactly once.
+type accountedClientStream struct {
+	grpc.ClientStream
+
+	logger        logging.Logger
+	logMessages   bool
+	serverStreams bool
+	start         time.Time
+
+	sentMsgs  atomic.Int64
+	sentBytes atomic.Int64
+	recvMsgs  atomic.Int64
+	recvBytes atomic.Int64
+
+	finishOnce sync.Once
+}
+
+func (s *accountedClientStream) SendMsg(m interface{}) error {
+	if err := s.ClientStream.SendMsg(m); err != nil {
+		// The real status will be returned by RecvMsg
+		// and logged with the completion line
+		s.logger.WithError(err).Warn("Cannot send RPC stream message")
+
+		return err
+	}
+
+	s.sentMsgs.Add(1)
+	s.sentBytes.Add(int64(payloadSize(m)))
+
+	if s.logMessages {
+		s.logger.WithFields(propertiesAsFields(nil, m, nil)).Info("Sent RPC stream message")
+	}
+
+	return nil
+}
+
+func (s *accountedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if err != nil {
+		if err == io.EOF {
+			s.finish(nil)
+		} else {
+			s.finish(err)
+		}
+
+		return err
+	}
+
+	s.recvMsgs.Add(1)
+	s.recvBytes.Add(int64(payloadSize(m)))
+
+	if s.logMessages {
+		s.logger.WithFields(propertiesAsFields(nil, nil, m)).Info("Received RPC stream message")
+	}
+
+	if !s.serverStreams {
+		// A single response message means the end of the stream
+		s.finish(nil)
+	}
+
+	return nil
+}
+
+// finish logs the stream completion line. Only the first call takes effect.
+func (s *accountedClientStream) finish(err error) {
+	if err == io.EOF {
+		err = nil
+	}
+
+	s.finishOnce.Do(func() {
+		entry := s.logger.WithFields(logging.Fields{
+			"grpc.code":              grpc_status.Code(err).String(),
+			"grpc.stream.sent_msgs":  s.sentMsgs.Load(),
+			"grpc.stream.sent_bytes": s.sentBytes.Load(),
+			"grpc.stream.recv_msgs":  s.recvMsgs.Load(),
+			"grpc.stream.recv_bytes": s.recvBytes.Load(),
+			"duration":               time.Since(s.start),
+		})
+
+		if err == nil {
+			entry.Info("Completed RPC stream")
+		} else {
+			entry.WithError(err).Error("Failed RPC stream")
+		}
+	})
+}
+
+func payloadSize(m interface{}) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+
+	return 0
+}
+
+// sensitiveMetadataKeys is the set of metadata keys whose values are never logged,
+// e.g. the tokens set manually or by per-RPC credentials.
+var sensitiveMetadataKeys = []string{"authorization", "proxy-authorization", "cookie", "x-api-key"}
+
+func propertiesAsFields(ctx context.Context, req, reply interface{}) logging.Fields {
+	fields := make(logging.Fields)
 
 	if ctx != nil {
 		if md, ok := grpc_metadata.FromOutgoingContext(ctx); ok {
 			// Request metadata
 			for k, v := range md {
-				if k == "request-id" {
+				if slices.Contains(sensitiveMetadataKeys, k) {
+					continue
+				}
+
+				if k == requestid.MetadataKey() {
 					k = "request.uid"
 				}
 
@@ -0,0 +1,156 @@
+package interceptors
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// startStreamServer starts a server that handles all streams with a given handler.
+func startStreamServer(t *testing.T, handler grpc.StreamHandler) *grpc.ClientConn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(handler))
+
+	go srv.Serve(l)
+
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// completionEntries waits for the stream completion lines to be logged and returns them.
+func completionEntries(hook *logtest.Hook) []*log.Entry {
+	var found []*log.Entry
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		found = found[:0]
+
+		for _, e := range hook.AllEntries() {
+			if e.Message == "Completed RPC stream" || e.Message == "Failed RPC stream" {
+				found = append(found, e)
+			}
+		}
+
+		if len(found) > 0 {
+			break
+		}
+	}
+
+	// Give a chance to log a duplicate line
+	time.Sleep(50 * time.Millisecond)
+
+	return found
+}
+
+func newStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return cc.NewStream(ctx, desc, method, opts...)
+}
+
+func TestWithStreamRequestLogging(t *testing.T) {
+	conn := startStreamServer(t, func(srv interface{}, ss grpc.ServerStream) error {
+		req := new(wrapperspb.StringValue)
+
+		if err := ss.RecvMsg(req); err != nil {
+			return err
+		}
+
+		if req.Value == "hang" {
+			<-ss.Context().Done()
+
+			return ss.Context().Err()
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := ss.SendMsg(wrapperspb.String("reply")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+
+	tests := []struct {
+		name     string
+		request  string
+		drain    bool
+		wantMsg  string
+		wantCode string
+		wantRecv int64
+	}{
+		{"drained", "ok", true, "Completed RPC stream", grpc_codes.OK.String(), 2},
+		{"canceled", "hang", false, "Failed RPC stream", grpc_codes.Canceled.String(), 0},
+	}
+
+	for _, tt := range tests {
+		l, hook := logtest.NewNullLogger()
+
+		interceptor := WithStreamRequestLogging(log.NewEntry(l))
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		cs, err := interceptor(ctx, desc, conn, "/test.Svc/Stream", newStream)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := cs.SendMsg(wrapperspb.String(tt.request)); err != nil {
+			t.Fatal(err)
+		}
+
+		cs.CloseSend()
+
+		if tt.drain {
+			for {
+				if err := cs.RecvMsg(new(wrapperspb.StringValue)); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+			}
+		} else {
+			// The stream is abandoned and canceled
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		cancel()
+
+		entries := completionEntries(hook)
+
+		if len(entries) != 1 {
+			t.Fatalf("%s: got %d completion lines, want 1", tt.name, len(entries))
+		}
+
+		e := entries[0]
+
+		if e.Message != tt.wantMsg || e.Data["grpc.code"] != tt.wantCode {
+			t.Errorf("%s: unexpected completion line: %s (code %v)", tt.name, e.Message, e.Data["grpc.code"])
+		}
+
+		if e.Data["grpc.stream.sent_msgs"] != int64(1) || e.Data["grpc.stream.recv_msgs"] != tt.wantRecv {
+			t.Errorf("%s: unexpected counters: %v", tt.name, e.Data)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package interceptors
 import (
 	"context"
 
-	"github.com/0xef53/go-grpc/utils"
+	"github.com/0xef53/go-grpc/requestid"
 
 	"google.golang.org/grpc"
 	grpc_metadata "google.golang.org/grpc/metadata"
@@ -11,7 +11,8 @@ import (
 
 // WithRequestIdentifier returns an unary client interceptor that appends an unique ID
 // to the context for the outgoing request.
-// If "request-id" is already present in the metadata, its value will be supplemented with a random string.
+// If the request ID is already present in the metadata, the new ID is appended to it
+// (see [requestid.Chain]).
 func WithRequestIdentifier() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		ctx = withRequestID(ctx)
@@ -22,7 +23,8 @@ func WithRequestIdentifier() grpc.UnaryClientInterceptor {
 
 // WithStreamRequestIdentifier returns a stream client interceptor that appends an unique ID
 // to the context for the outgoing request.
-// If "request-id" is already present in the metadata, its value will be supplemented with a random string.
+// If the request ID is already present in the metadata, the new ID is appended to it
+// (see [requestid.Chain]).
 func WithStreamRequestIdentifier() grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		ctx = withRequestID(ctx)
@@ -32,29 +34,23 @@ func WithStreamRequestIdentifier() grpc.StreamClientInterceptor {
 }
 
 func withRequestID(ctx context.Context) context.Context {
-	/*
-			TODO:
-				Perhaps it would be more correct to use this code:
+	key := requestid.MetadataKey()
 
-		return metadata.AppendToOutgoingContext(ctx, "request-id", utils.NewRequestID())
-	*/
-
-	reqID := utils.NewRequestID()
+	reqID := requestid.New()
 
 	// When creating a new outgoing ID, we rely on the fact that the original incoming request ID
-	// was added to OutgoingMetadata by the server interceptor:
-	// https://gitlab.netangels.ru/golang/grpc/-/blob/master/server/interceptors/request_id.go
+	// was added to OutgoingMetadata by the server interceptor.
 	if md, ok := grpc_metadata.FromOutgoingContext(ctx); ok {
-		if v, ok := md["request-id"]; ok {
-			reqID = v[0] + ":" + reqID
+		if v := md.Get(key); len(v) > 0 {
+			reqID = requestid.Chain(v[len(v)-1], reqID)
 		}
 
-		md.Set("request-id", reqID)
+		md = md.Copy()
+
+		md.Set(key, reqID)
 
-		ctx = grpc_metadata.NewOutgoingContext(ctx, md)
-	} else {
-		ctx = grpc_metadata.AppendToOutgoingContext(ctx, "request-id", reqID)
+		return grpc_metadata.NewOutgoingContext(ctx, md)
 	}
 
-	return ctx
+	return grpc_metadata.AppendToOutgoingContext(ctx, key, reqID)
 }
@@ -2,41 +2,365 @@ package interceptors
 
 import (
 	"context"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/proto/method"
+	"github.com/0xef53/go-grpc/status"
 	"github.com/0xef53/go-grpc/utils"
 
 	"google.golang.org/grpc"
 	grpc_codes "google.golang.org/grpc/codes"
+	grpc_metadata "google.golang.org/grpc/metadata"
 	grpc_status "google.golang.org/grpc/status"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// WithRequestsRetries returns an unary client interceptor that retries a request
-// that fail due to temporary failures (such as network problems or service unavailability).
-// It performs up to maxAttempts retries with a delay in seconds between attempts.
-func WithRequestsRetries(maxAttempts int, delay time.Duration) grpc.UnaryClientInterceptor {
-	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		logger := log.WithField("request.uid", utils.ExtractRequestID(ctx))
+// RetryPolicy describes how failed calls are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff limits the delay between attempts.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor by which the delay is multiplied after each retry.
+	Multiplier float64
+
+	// Jitter is the relative random deviation of the delay in the range [0, 1].
+	// For example, 0.2 means that the delay varies within ±20%.
+	Jitter float64
+
+	// PerAttemptTimeout, if not zero, limits the duration of each attempt
+	// of unary calls. An attempt that exceeds it is retried if the overall
+	// deadline has not yet expired.
+	PerAttemptTimeout time.Duration
+
+	// RetryableCodes is the set of codes on which calls are retried.
+	RetryableCodes []grpc_codes.Code
+
+	// Idempotent reports whether a given method can be safely retried.
+	// By default, the standard "idempotency_level" method option is used
+	// (see [method.Idempotent]). Calls of non-idempotent methods are not retried.
+	Idempotent func(fullMethod string) bool
+}
+
+// DefaultRetryPolicy returns a policy with reasonable default values.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableCodes: []grpc_codes.Code{grpc_codes.Unavailable},
+		Idempotent:     method.Idempotent,
+	}
+}
 
-		var err error
+// backoff returns the delay before the retry that follows a given attempt (starting from 1).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
 
-		for attempt := 0; attempt < maxAttempts; attempt++ {
-			err = invoker(ctx, method, req, reply, cc, opts...)
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(d)
+}
+
+// retryable reports whether a call of a given method failed with a given error
+// can be retried.
+func (p *RetryPolicy) retryable(fullMethod string, err error) bool {
+	return slices.Contains(p.RetryableCodes, grpc_status.Code(err)) && p.idempotent(fullMethod)
+}
+
+func (p *RetryPolicy) idempotent(fullMethod string) bool {
+	if p.Idempotent != nil {
+		return p.Idempotent(fullMethod)
+	}
+
+	return method.Idempotent(fullMethod)
+}
+
+// wait sleeps before the next attempt. The delay is taken from the server's
+// pushback (see [status.RetryDelay]) if it is present, otherwise it is calculated
+// according to the policy. It returns false if the context is done
+// or its deadline expires before the end of the delay.
+func (p *RetryPolicy) wait(ctx context.Context, logger logging.Logger, attempt int, err error) bool {
+	delay, ok := status.RetryDelay(err)
+	if !ok {
+		delay = p.backoff(attempt)
+	}
+
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < delay {
+		return false
+	}
 
-			if grpc_status.Code(err) == grpc_codes.Unavailable {
-				logger.Warnf("Failed to perform request (attempt = %d): %s, %s", attempt, err, ctx.Err())
-				logger.Warnf("Next try after %d seconds", delay)
+	logger.WithError(err).WithFields(logging.Fields{"attempt": attempt, "delay": delay.String()}).Warn("Failed to perform request, retrying")
 
-				time.Sleep(delay * time.Second)
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+	}
+
+	return true
+}
+
+// WithRetries returns an unary client interceptor that retries failed calls
+// according to a given policy.
+func WithRetries(policy RetryPolicy, logger logging.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		logger := logger.WithFields(logging.Fields{"request.uid": requestIDForLog(ctx), "grpc.method": method})
+
+		for attempt := 1; ; attempt++ {
+			err := invokeAttempt(ctx, policy.PerAttemptTimeout, method, req, reply, cc, invoker, opts...)
+			if err == nil {
+				return nil
+			}
+
+			if grpc_status.Code(err) == grpc_codes.DeadlineExceeded && policy.PerAttemptTimeout > 0 && ctx.Err() == nil {
+				// The attempt timed out, but the call still can be completed
+				if attempt >= policy.MaxAttempts || !policy.idempotent(method) || !policy.wait(ctx, logger, attempt, err) {
+					return err
+				}
 
 				continue
 			}
 
-			break
+			if attempt >= policy.MaxAttempts || !policy.retryable(method, err) || !policy.wait(ctx, logger, attempt, err) {
+				return err
+			}
+		}
+	}
+}
+
+func invokeAttempt(ctx context.Context, timeout time.Duration, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// WithStreamRetries returns a stream client interceptor that retries failed calls
+// according to a given policy.
+//
+// Establishing of all streams is retried. Server streams with a single request
+// (not client or bidirectional streams) are also retried until the first response
+// message is received. The per-attempt timeout is not applied to streams.
+func WithStreamRetries(policy RetryPolicy, logger logging.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		logger := logger.WithFields(logging.Fields{"request.uid": requestIDForLog(ctx), "grpc.method": method})
+
+		newStream := func() (grpc.ClientStream, int, error) {
+			for attempt := 1; ; attempt++ {
+				cs, err := streamer(ctx, desc, cc, method, opts...)
+				if err == nil {
+					return cs, attempt, nil
+				}
+
+				if attempt >= policy.MaxAttempts || !policy.retryable(method, err) || !policy.wait(ctx, logger, attempt, err) {
+					return nil, attempt, err
+				}
+			}
+		}
+
+		cs, attempt, err := newStream()
+		if err != nil {
+			return nil, err
+		}
+
+		if desc.ClientStreams {
+			return cs, nil
+		}
+
+		return &retriableClientStream{
+			ClientStream: cs,
+			ctx:          ctx,
+			method:       method,
+			policy:       &policy,
+			logger:       logger,
+			attempt:      attempt,
+			newStream: func() (grpc.ClientStream, error) {
+				return streamer(ctx, desc, cc, method, opts...)
+			},
+		}, nil
+	}
+}
+
+// retriableClientStream re-creates a server stream and re-sends the request
+// if the stream fails before the first response message is received.
+type retriableClientStream struct {
+	grpc.ClientStream
+
+	mu sync.Mutex
+
+	ctx    context.Context
+	method string
+	policy *RetryPolicy
+	logger logging.Logger
+
+	newStream func() (grpc.ClientStream, error)
+
+	attempt   int
+	req       interface{}
+	closeSent bool
+	received  bool
+}
+
+func (s *retriableClientStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	s.req = m
+	cs := s.ClientStream
+	s.mu.Unlock()
+
+	return cs.SendMsg(m)
+}
+
+func (s *retriableClientStream) CloseSend() error {
+	s.mu.Lock()
+	s.closeSent = true
+	cs := s.ClientStream
+	s.mu.Unlock()
+
+	return cs.CloseSend()
+}
+
+func (s *retriableClientStream) current() grpc.ClientStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ClientStream
+}
+
+func (s *retriableClientStream) Header() (grpc_metadata.MD, error) {
+	return s.current().Header()
+}
+
+func (s *retriableClientStream) Trailer() grpc_metadata.MD {
+	return s.current().Trailer()
+}
+
+func (s *retriableClientStream) Context() context.Context {
+	return s.current().Context()
+}
+
+func (s *retriableClientStream) RecvMsg(m interface{}) error {
+	err := s.current().RecvMsg(m)
+
+	for err != nil {
+		s.mu.Lock()
+
+		canRetry := !s.received && s.req != nil && s.attempt < s.policy.MaxAttempts
+		attempt := s.attempt
+
+		s.mu.Unlock()
+
+		// The lock is not held while waiting, so that the other methods
+		// of the stream are not blocked
+		if !canRetry || !s.policy.retryable(s.method, err) || !s.policy.wait(s.ctx, s.logger, attempt, err) {
+			return err
 		}
 
+		s.mu.Lock()
+
+		s.attempt++
+
+		err = s.retry()
+
+		cs := s.ClientStream
+
+		s.mu.Unlock()
+
+		if err == nil {
+			err = cs.RecvMsg(m)
+		}
+	}
+
+	s.mu.Lock()
+	s.received = true
+	s.mu.Unlock()
+
+	return nil
+}
+
+// retry re-creates the stream and re-sends the request.
+// It must be called with s.mu held.
+func (s *retriableClientStream) retry() error {
+	cs, err := s.newStream()
+	if err != nil {
 		return err
 	}
+
+	s.ClientStream = cs
+
+	if err := cs.SendMsg(s.req); err != nil {
+		if err == io.EOF {
+			// The stream was aborted, the actual status is returned by RecvMsg
+			return nil
+		}
+
+		return err
+	}
+
+	if s.closeSent {
+		return cs.CloseSend()
+	}
+
+	return nil
+}
+
+// requestIDForLog returns the request ID of the call or an empty string.
+func requestIDForLog(ctx context.Context) string {
+	reqID, _ := utils.RequestIDFromContext(ctx)
+
+	return reqID
+}
+
+// WithRequestsRetries returns an unary client interceptor that retries a request
+// that fail due to temporary failures (such as network problems or service unavailability).
+// It performs up to maxAttempts attempts with a given constant delay between them.
+// All methods are considered idempotent.
+//
+// For compatibility, a delay less than a microsecond is treated as a number
+// of seconds: WithRequestsRetries(5, 2) waits two seconds between attempts,
+// as well as WithRequestsRetries(5, 2*time.Second).
+//
+// Deprecated: use [WithRetries] instead.
+func WithRequestsRetries(maxAttempts int, delay time.Duration) grpc.UnaryClientInterceptor {
+	if delay < time.Microsecond {
+		delay *= time.Second
+	}
+
+	policy := RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: delay,
+		MaxBackoff:     delay,
+		Multiplier:     1,
+		RetryableCodes: []grpc_codes.Code{grpc_codes.Unavailable},
+		Idempotent:     func(string) bool { return true },
+	}
+
+	return WithRetries(policy, logging.NewLogrusLogger(log.NewEntry(log.StandardLogger())))
 }
@@ -0,0 +1,274 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/status"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+func testRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+
+	p.InitialBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	p.Idempotent = func(fullMethod string) bool { return fullMethod != "/test.Svc/Create" }
+
+	return p
+}
+
+func failingInvoker(calls *int, errs ...error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*calls++
+
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+
+		return nil
+	}
+}
+
+func TestWithRetries(t *testing.T) {
+	unavailable := grpc_status.Error(grpc_codes.Unavailable, "unavailable")
+
+	tests := []struct {
+		name      string
+		method    string
+		errs      []error
+		wantCalls int
+		wantCode  grpc_codes.Code
+	}{
+		{"success after retries", "/test.Svc/Get", []error{unavailable, unavailable}, 3, grpc_codes.OK},
+		{"attempts exhausted", "/test.Svc/Get", []error{unavailable, unavailable, unavailable, unavailable, unavailable}, 4, grpc_codes.Unavailable},
+		{"non-retryable code", "/test.Svc/Get", []error{grpc_status.Error(grpc_codes.NotFound, "not found")}, 1, grpc_codes.NotFound},
+		{"non-idempotent method", "/test.Svc/Create", []error{unavailable}, 1, grpc_codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+
+		interceptor := WithRetries(testRetryPolicy(), logging.Discard)
+
+		err := interceptor(context.Background(), tt.method, nil, nil, nil, failingInvoker(&calls, tt.errs...))
+
+		if calls != tt.wantCalls {
+			t.Errorf("%s: unexpected number of calls: got %d, want %d", tt.name, calls, tt.wantCalls)
+		}
+
+		if code := grpc_status.Code(err); code != tt.wantCode {
+			t.Errorf("%s: unexpected code: got %s, want %s", tt.name, code, tt.wantCode)
+		}
+	}
+}
+
+func TestWithRetriesPushback(t *testing.T) {
+	pushback := status.Error(context.Background(), grpc_codes.Unavailable, "drain", status.RetryInfo(50*time.Millisecond))
+
+	calls := 0
+	start := time.Now()
+
+	err := WithRetries(testRetryPolicy(), logging.Discard)(context.Background(), "/test.Svc/Get", nil, nil, nil, failingInvoker(&calls, pushback))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("the server pushback was not respected: retried after %s", d)
+	}
+
+	// The pushback exceeds the deadline
+	calls = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = WithRetries(testRetryPolicy(), logging.Discard)(ctx, "/test.Svc/Get", nil, nil, nil, failingInvoker(&calls, pushback))
+	if calls != 1 || !errors.Is(err, pushback) {
+		t.Errorf("unexpected result: calls = %d, err = %v", calls, err)
+	}
+}
+
+func TestWithRetriesContextCanceled(t *testing.T) {
+	policy := testRetryPolicy()
+
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	calls := 0
+
+	err := WithRetries(policy, logging.Discard)(ctx, "/test.Svc/Get", nil, nil, nil, failingInvoker(&calls, grpc_status.Error(grpc_codes.Unavailable, "unavailable")))
+
+	if calls != 1 || grpc_status.Code(err) != grpc_codes.Unavailable {
+		t.Errorf("unexpected result: calls = %d, err = %v", calls, err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2, Jitter: 0.2}
+
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		d := p.backoff(attempt)
+
+		if d < want*8/10 || d > want*12/10 {
+			t.Errorf("attempt %d: delay %s is out of range around %s", attempt, d, want)
+		}
+	}
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+
+	recvErr error
+	sendErr error
+	sent    []interface{}
+}
+
+func (s *fakeClientStream) SendMsg(m interface{}) error { s.sent = append(s.sent, m); return s.sendErr }
+func (s *fakeClientStream) CloseSend() error            { return nil }
+func (s *fakeClientStream) RecvMsg(m interface{}) error { return s.recvErr }
+func (s *fakeClientStream) Context() context.Context    { return context.Background() }
+
+func TestWithStreamRetries(t *testing.T) {
+	var streams []*fakeClientStream
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		s := &fakeClientStream{}
+
+		// The first two streams fail before the first message
+		if len(streams) < 2 {
+			s.recvErr = grpc_status.Error(grpc_codes.Unavailable, "unavailable")
+		}
+
+		streams = append(streams, s)
+
+		return s, nil
+	}
+
+	cs, err := WithStreamRetries(testRetryPolicy(), logging.Discard)(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/test.Svc/Watch", streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs.SendMsg("request")
+	cs.CloseSend()
+
+	if err := cs.RecvMsg(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(streams) != 3 {
+		t.Fatalf("unexpected number of streams: %d", len(streams))
+	}
+
+	for i, s := range streams {
+		if len(s.sent) != 1 || s.sent[0] != "request" {
+			t.Errorf("stream %d: the request was not re-sent: %v", i, s.sent)
+		}
+	}
+}
+
+func TestWithStreamRetriesUnlockedWait(t *testing.T) {
+	pushback := status.Error(context.Background(), grpc_codes.Unavailable, "drain", status.RetryInfo(300*time.Millisecond))
+
+	var n int
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		n++
+
+		if n == 1 {
+			return &fakeClientStream{recvErr: pushback}, nil
+		}
+
+		return &fakeClientStream{}, nil
+	}
+
+	cs, err := WithStreamRetries(testRetryPolicy(), logging.Discard)(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/test.Svc/Watch", streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs.SendMsg("request")
+
+	done := make(chan error, 1)
+
+	go func() { done <- cs.RecvMsg(nil) }()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// The stream must remain usable while RecvMsg waits for the next attempt
+	start := time.Now()
+
+	cs.Context()
+
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("Context() was blocked for %s", d)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithStreamRetriesResendFailure(t *testing.T) {
+	var n int
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		n++
+
+		if n == 1 {
+			return &fakeClientStream{recvErr: grpc_status.Error(grpc_codes.Unavailable, "unavailable")}, nil
+		}
+
+		// The new stream is aborted by the server before the request is re-sent
+		return &fakeClientStream{sendErr: io.EOF, recvErr: grpc_status.Error(grpc_codes.PermissionDenied, "denied")}, nil
+	}
+
+	cs, err := WithStreamRetries(testRetryPolicy(), logging.Discard)(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/test.Svc/Watch", streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs.SendMsg("request")
+
+	if code := grpc_status.Code(cs.RecvMsg(nil)); code != grpc_codes.PermissionDenied {
+		t.Fatalf("got invalid code: want %s, got %s", grpc_codes.PermissionDenied, code)
+	}
+}
+
+func TestWithRequestsRetriesDelayUnit(t *testing.T) {
+	unavailable := grpc_status.Error(grpc_codes.Unavailable, "unavailable")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	// A bare number is the delay in seconds, which exceeds the deadline, so there is no retry
+	var calls int
+
+	err := WithRequestsRetries(3, 1)(ctx, "/test.Svc/Create", nil, nil, nil, failingInvoker(&calls, unavailable, unavailable))
+
+	if calls != 1 || grpc_status.Code(err) != grpc_codes.Unavailable {
+		t.Fatalf("got unexpected result: calls = %d, err = %v", calls, err)
+	}
+
+	// A duration is used as is
+	calls = 0
+
+	err = WithRequestsRetries(3, 10*time.Millisecond)(ctx, "/test.Svc/Create", nil, nil, nil, failingInvoker(&calls, unavailable, unavailable))
+
+	if calls != 3 || err != nil {
+		t.Fatalf("got unexpected result: calls = %d, err = %v", calls, err)
+	}
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ErrPoolClosed is returned by [Pool.Get] after the pool is closed.
+var ErrPoolClosed = errors.New("connection pool is closed")
+
+// Pool is a concurrency-safe cache of shared client connections keyed by target
+// and TLS configuration. Connections are shared only between the calls of [Pool.Get]
+// with the same *tls.Config value, so the configuration should be created once and reused.
+//
+// Connections are reference-counted: each [Pool.Get] must be paired with
+// the Close method of the returned connection. A connection that is not used
+// by anyone for the idle timeout is closed and removed from the pool.
+type Pool struct {
+	mu sync.Mutex
+
+	conns       map[poolKey]*poolEntry
+	idleTimeout time.Duration
+	opts        []grpc.DialOption
+	closed      bool
+}
+
+type poolKey struct {
+	target string
+
+	// Comparing the configurations field by field is not reliable,
+	// since they contain callbacks and certificate pools
+	tlsConfig *tls.Config
+}
+
+type poolEntry struct {
+	key  poolKey
+	conn *grpc.ClientConn
+	refs int
+
+	// Closed when the connection is created or the creation fails with err
+	ready chan struct{}
+	err   error
+
+	// Fires when the connection has been idle for the idle timeout
+	idleTimer *time.Timer
+
+	cancel context.CancelFunc
+}
+
+// NewPool returns a new connection pool. Connections that have no references
+// are closed after a given idle timeout. If it is zero, they remain open until
+// the pool is closed.
+//
+// The dial options are used for all connections of the pool in addition
+// to the default ones (see [NewSecureConnection]).
+func NewPool(idleTimeout time.Duration, opts ...grpc.DialOption) *Pool {
+	return &Pool{
+		conns:       make(map[poolKey]*poolEntry),
+		idleTimeout: idleTimeout,
+		opts:        opts,
+	}
+}
+
+// Get returns a shared connection to the specified target. If tlsConfig is nil,
+// the connection is insecure. The target has the same format as in [NewSecureConnection].
+//
+// The returned connection must be closed when it is no longer needed.
+// This only releases the reference, the underlying connection is closed by the pool.
+func (p *Pool) Get(hostport string, tlsConfig *tls.Config) (*PooledConn, error) {
+	key := poolKey{
+		target:    strings.TrimSpace(hostport),
+		tlsConfig: tlsConfig,
+	}
+
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+
+		return nil, ErrPoolClosed
+	}
+
+	e, ok := p.conns[key]
+	if !ok {
+		e = &poolEntry{key: key, ready: make(chan struct{})}
+
+		p.conns[key] = e
+
+		p.mu.Unlock()
+
+		// The connection is created without holding the lock, since it may wait
+		// until the connection is ready (see [WaitUntilReady])
+		if err := p.dial(e); err != nil {
+			return nil, err
+		}
+
+		p.mu.Lock()
+	} else if !e.dialed() {
+		p.mu.Unlock()
+
+		<-e.ready
+
+		if e.err != nil {
+			return nil, e.err
+		}
+
+		// The entry may have been evicted in the meantime
+		return p.Get(hostport, tlsConfig)
+	}
+
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	if e.idleTimer != nil {
+		e.idleTimer.Stop()
+		e.idleTimer = nil
+	}
+
+	e.refs++
+
+	return &PooledConn{ClientConn: e.conn, pool: p, entry: e}, nil
+}
+
+// dial creates the connection of a given entry and wakes up
+// the callers waiting for it.
+func (p *Pool) dial(e *poolEntry) error {
+	conn, err := newConnection(e.key.target, e.key.tlsConfig, p.opts...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	defer close(e.ready)
+
+	if err != nil {
+		e.err = err
+
+		if p.conns[e.key] == e {
+			delete(p.conns, e.key)
+		}
+
+		return err
+	}
+
+	if p.closed || p.conns[e.key] != e {
+		conn.Close()
+
+		e.err = ErrPoolClosed
+
+		return e.err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e.conn = conn
+	e.cancel = cancel
+
+	go logStateChanges(ctx, conn, e.key.target)
+
+	return nil
+}
+
+// release decrements the reference counter of a given entry and schedules
+// its eviction when it becomes unused.
+func (p *Pool) release(e *poolEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.conns[e.key] != e {
+		return
+	}
+
+	if e.refs--; e.refs > 0 || p.idleTimeout == 0 {
+		return
+	}
+
+	var t *time.Timer
+
+	t = time.AfterFunc(p.idleTimeout, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		// The connection may have been taken again in the meantime
+		if p.conns[e.key] != e || e.idleTimer != t {
+			return
+		}
+
+		delete(p.conns, e.key)
+
+		logger.WithField("server", e.key.target).Debug("Closing idle pooled connection")
+
+		e.close()
+	})
+
+	e.idleTimer = t
+}
+
+// Len returns the number of open connections in the pool.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.conns)
+}
+
+// Close closes all connections of the pool regardless of their references.
+// Connections cannot be obtained from the closed pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+
+	var errs []error
+
+	for key, e := range p.conns {
+		if e.idleTimer != nil {
+			e.idleTimer.Stop()
+		}
+
+		errs = append(errs, e.close())
+
+		delete(p.conns, key)
+	}
+
+	return errors.Join(errs...)
+}
+
+// dialed reports whether the creation of the connection is finished.
+func (e *poolEntry) dialed() bool {
+	select {
+	case <-e.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *poolEntry) close() error {
+	if e.conn == nil {
+		// Still being created, it is closed by the dial method
+		return nil
+	}
+
+	e.cancel()
+
+	return e.conn.Close()
+}
+
+// PooledConn is a connection obtained from the [Pool].
+type PooledConn struct {
+	*grpc.ClientConn
+
+	pool  *Pool
+	entry *poolEntry
+	once  sync.Once
+}
+
+// Close releases the connection back to the pool. It does not close
+// the underlying connection, which may be used by others.
+func (c *PooledConn) Close() error {
+	c.once.Do(func() { c.pool.release(c.entry) })
+
+	return nil
+}
+
+// logStateChanges logs the connectivity state transitions of a given connection
+// until the context is canceled.
+func logStateChanges(ctx context.Context, conn *grpc.ClientConn, target string) {
+	state := conn.GetState()
+
+	for conn.WaitForStateChange(ctx, state) {
+		prev := state
+
+		state = conn.GetState()
+
+		entry := logger.WithFields(logging.Fields{"server": target, "state.prev": prev.String(), "state": state.String()})
+
+		if state == connectivity.TransientFailure {
+			entry.Warn("Connection state changed")
+		} else {
+			entry.Info("Connection state changed")
+		}
+
+		if state == connectivity.Shutdown {
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestPoolSharing(t *testing.T) {
+	p := NewPool(0)
+	defer p.Close()
+
+	c1, err := p.Get("127.0.0.1:19999", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c2, err := p.Get("127.0.0.1:19999", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c1.ClientConn != c2.ClientConn {
+		t.Fatal("expected the same connection for the same target")
+	}
+
+	c3, err := p.Get("127.0.0.1:19999", &tls.Config{ServerName: "example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c3.ClientConn == c1.ClientConn {
+		t.Fatal("expected different connections for different TLS identities")
+	}
+
+	// The same server name, but different verification settings
+	c4, err := p.Get("127.0.0.1:19999", &tls.Config{ServerName: "example.org", InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c4.ClientConn == c3.ClientConn {
+		t.Fatal("expected different connections for different TLS configurations")
+	}
+
+	if n := p.Len(); n != 3 {
+		t.Fatalf("expected 3 connections, got %d", n)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Releasing after the pool is closed is a no-op
+	c1.Close()
+
+	if _, err := p.Get("127.0.0.1:19999", nil); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPoolIdleEviction(t *testing.T) {
+	p := NewPool(50 * time.Millisecond)
+	defer p.Close()
+
+	c1, _ := p.Get("127.0.0.1:19999", nil)
+	c2, _ := p.Get("127.0.0.1:19999", nil)
+
+	c1.Close()
+	c1.Close() // a repeated call does not release the reference again
+
+	time.Sleep(100 * time.Millisecond)
+
+	if n := p.Len(); n != 1 {
+		t.Fatalf("connection in use was evicted")
+	}
+
+	c2.Close()
+
+	// Taking the connection again cancels the eviction
+	c3, _ := p.Get("127.0.0.1:19999", nil)
+
+	if c3.ClientConn != c2.ClientConn {
+		t.Fatal("expected the same connection")
+	}
+
+	c3.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if n := p.Len(); n != 0 {
+		t.Fatalf("idle connection was not evicted")
+	}
+}
+
+func TestPoolSlowTarget(t *testing.T) {
+	// The slow target accepts connections, but never completes the handshake
+	slow, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+
+	go func() {
+		for {
+			c, err := slow.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	p := NewPool(0, WaitUntilReady(time.Second))
+	defer p.Close()
+
+	slowErrs := make(chan error, 2)
+
+	// Both callers wait for the same connection attempt
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := p.Get(slow.Addr().String(), nil)
+
+			slowErrs <- err
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+
+	c, err := p.Get(l.Addr().String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("the slow target blocked the pool for %s", d)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := <-slowErrs; !errors.Is(err, ErrNotReady) {
+			t.Fatalf("expected ErrNotReady, got %v", err)
+		}
+	}
+
+	if n := p.Len(); n != 1 {
+		t.Fatalf("the failed connection was kept in the pool: %d connections", n)
+	}
+}
@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+// WeightedRoundRobin is the name of the load balancing policy that distributes
+// calls across the ready endpoints in proportion to their weights read from
+// the endpoints file (see [Endpoint]). Addresses without a weight, e.g. the ones
+// produced by other resolvers, have a weight of 1.
+//
+// The endpoints are checked using the standard health checking service
+// if it is enabled in the service config.
+const WeightedRoundRobin = "weighted_round_robin_static"
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(WeightedRoundRobin, &weightedPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+type weightedPickerBuilder struct{}
+
+func (*weightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	p := &weightedPicker{
+		items: make([]*weightedItem, 0, len(info.ReadySCs)),
+	}
+
+	for sc, sci := range info.ReadySCs {
+		weight := int64(1)
+
+		if ep, ok := EndpointInfo(sci.Address); ok && ep.Weight > 0 {
+			weight = int64(ep.Weight)
+		}
+
+		p.items = append(p.items, &weightedItem{sc: sc, addr: sci.Address.Addr, weight: weight})
+
+		p.total += weight
+	}
+
+	// The map iteration order is random
+	sort.Slice(p.items, func(i, j int) bool { return p.items[i].addr < p.items[j].addr })
+
+	return p
+}
+
+type weightedItem struct {
+	sc      balancer.SubConn
+	addr    string
+	weight  int64
+	current int64
+}
+
+// weightedPicker implements the smooth weighted round-robin algorithm,
+// which spreads the picks of each endpoint evenly over the cycle.
+type weightedPicker struct {
+	mu sync.Mutex
+
+	items []*weightedItem
+	total int64
+}
+
+func (p *weightedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *weightedItem
+
+	for _, item := range p.items {
+		item.current += item.weight
+
+		if best == nil || item.current > best.current {
+			best = item
+		}
+	}
+
+	best.current -= p.total
+
+	return balancer.PickResult{SubConn: best.sc}, nil
+}
@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestWeightedRoundRobin(t *testing.T) {
+	calls := make(chan [2]string, 10)
+
+	heavy, light := startAuthorityServer(t, calls), startAuthorityServer(t, calls)
+
+	filename := filepath.Join(t.TempDir(), "endpoints.json")
+
+	content := fmt.Sprintf(`[{"address": %q, "weight": 3}, {"address": %q}]`, heavy, light)
+
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := grpc.NewClient(
+		FileTarget(filename),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, WeightedRoundRobin)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	invoke := func() string {
+		if err := conn.Invoke(context.Background(), "/test.Svc/Get", new(emptypb.Empty), new(emptypb.Empty), grpc.WaitForReady(true)); err != nil {
+			t.Fatal(err)
+		}
+
+		return (<-calls)[1]
+	}
+
+	// Wait until both endpoints are connected
+	seen := make(map[string]bool)
+
+	for i := 0; i < 50 && len(seen) < 2; i++ {
+		seen[invoke()] = true
+	}
+
+	counts := make(map[string]int)
+
+	for i := 0; i < 40; i++ {
+		counts[invoke()]++
+	}
+
+	if counts[heavy] != 30 || counts[light] != 10 {
+		t.Fatalf("the calls were not distributed according to the weights: %v", counts)
+	}
+}
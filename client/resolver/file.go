@@ -0,0 +1,334 @@
+package resolver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+)
+
+// FileScheme is the scheme of the file resolver.
+const FileScheme = "file"
+
+// FilePollInterval is the interval between checks of the endpoints file for changes.
+var FilePollInterval = 5 * time.Second
+
+func init() {
+	resolver.Register(&fileBuilder{})
+}
+
+// FileTarget returns a target for a given endpoints file, e.g. "file:///etc/app/endpoints.json".
+// The file name must be absolute.
+func FileTarget(filename string) string {
+	return FileScheme + "://" + filepath.ToSlash(filename)
+}
+
+// Endpoint describes a backend endpoint read from the endpoints file.
+type Endpoint struct {
+	Address string `json:"address"`
+
+	// ServerName is the authority and the name for TLS verification
+	// of the connections to the endpoint. If empty, the host of the address is used.
+	ServerName string `json:"server_name"`
+
+	// Weight is used by the [WeightedRoundRobin] policy. If zero, it is 1.
+	Weight uint32 `json:"weight"`
+
+	Metadata map[string]string `json:"metadata"`
+}
+
+type endpointInfoKey struct{}
+
+// endpointInfo is stored in the address attributes. The attribute values must be
+// comparable or implement the Equal method, which is not the case for Endpoint.
+type endpointInfo struct {
+	ep Endpoint
+}
+
+func (i *endpointInfo) Equal(o any) bool {
+	oi, ok := o.(*endpointInfo)
+
+	return ok && reflect.DeepEqual(i.ep, oi.ep)
+}
+
+// EndpointInfo returns the weight and the metadata of the endpoint a given address
+// was resolved from. They are stored in the address attributes and are used by
+// the [WeightedRoundRobin] policy: the built-in policies (round_robin, pick_first) ignore them.
+func EndpointInfo(addr resolver.Address) (Endpoint, bool) {
+	if info, ok := addr.Attributes.Value(endpointInfoKey{}).(*endpointInfo); ok {
+		return info.ep, true
+	}
+
+	return Endpoint{}, false
+}
+
+// ReadEndpointsFile reads the list of endpoints from a JSON or INI file.
+//
+// The format is determined by the file extension: ".ini" and ".conf" files are
+// parsed as INI, all others as JSON.
+//
+// JSON format (the top-level object can be replaced with the array):
+//
+//	{"endpoints": [{"address": "10.0.0.1:9191", "server_name": "backend.example.com", "weight": 2, "metadata": {"zone": "a"}}]}
+//
+// INI format:
+//
+//	[endpoint]
+//	address = 10.0.0.1:9191
+//	server_name = backend.example.com
+//	weight = 2
+//	metadata.zone = a
+func ReadEndpointsFile(filename string) ([]Endpoint, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseEndpoints(filename, b)
+}
+
+func parseEndpoints(filename string, b []byte) ([]Endpoint, error) {
+	var endpoints []Endpoint
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ini", ".conf":
+		endpoints, err = parseEndpointsINI(b)
+	default:
+		endpoints, err = parseEndpointsJSON(b)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse endpoints file %s: %w", filename, err)
+	}
+
+	for idx := range endpoints {
+		if len(endpoints[idx].Address) == 0 {
+			return nil, fmt.Errorf("cannot parse endpoints file %s: endpoint #%d has no address", filename, idx+1)
+		}
+
+		if len(endpoints[idx].ServerName) == 0 {
+			endpoints[idx].ServerName = serverName(endpoints[idx].Address)
+		}
+
+		if endpoints[idx].Weight == 0 {
+			endpoints[idx].Weight = 1
+		}
+	}
+
+	return endpoints, nil
+}
+
+func parseEndpointsJSON(b []byte) ([]Endpoint, error) {
+	var endpoints []Endpoint
+
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		if err := json.Unmarshal(b, &endpoints); err != nil {
+			return nil, err
+		}
+
+		return endpoints, nil
+	}
+
+	var v struct {
+		Endpoints []Endpoint `json:"endpoints"`
+	}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	return v.Endpoints, nil
+}
+
+func parseEndpointsINI(b []byte) ([]Endpoint, error) {
+	var endpoints []Endpoint
+	var cur *Endpoint
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case len(line) == 0, line[0] == ';', line[0] == '#':
+			continue
+		case line[0] == '[':
+			if line != "[endpoint]" {
+				return nil, fmt.Errorf("line %d: unknown section %s", lineno, line)
+			}
+
+			endpoints = append(endpoints, Endpoint{})
+
+			cur = &endpoints[len(endpoints)-1]
+
+			continue
+		}
+
+		if cur == nil {
+			return nil, fmt.Errorf("line %d: key outside of the [endpoint] section", lineno)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid line: %s", lineno, line)
+		}
+
+		key, value = strings.TrimSpace(key), strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch {
+		case key == "address":
+			cur.Address = value
+		case key == "server_name":
+			cur.ServerName = value
+		case key == "weight":
+			w, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid weight: %s", lineno, value)
+			}
+
+			cur.Weight = uint32(w)
+		case strings.HasPrefix(key, "metadata."):
+			if cur.Metadata == nil {
+				cur.Metadata = make(map[string]string)
+			}
+
+			cur.Metadata[strings.TrimPrefix(key, "metadata.")] = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key: %s", lineno, key)
+		}
+	}
+
+	return endpoints, scanner.Err()
+}
+
+type fileBuilder struct{}
+
+func (*fileBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	filename := target.URL.Path
+
+	if len(filename) == 0 {
+		return nil, fmt.Errorf("no file name in target: %s", target.URL.String())
+	}
+
+	r := &fileResolver{
+		filename: filename,
+		cc:       cc,
+		resolve:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	// The first resolution is synchronous to report errors early
+	if err := r.update(); err != nil {
+		return nil, err
+	}
+
+	go r.watch()
+
+	return r, nil
+}
+
+func (*fileBuilder) Scheme() string {
+	return FileScheme
+}
+
+// fileResolver polls the endpoints file and pushes its changes to the ClientConn.
+type fileResolver struct {
+	filename string
+	cc       resolver.ClientConn
+
+	// Checksum of the last applied file content
+	checksum [sha256.Size]byte
+
+	resolve chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (r *fileResolver) watch() {
+	ticker := time.NewTicker(FilePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.resolve:
+		}
+
+		if err := r.update(); err != nil {
+			// The last good state is kept
+			logger.WithField("filename", r.filename).WithError(err).Error("Cannot update endpoints")
+		}
+	}
+}
+
+// update reads the file and pushes the endpoints to the ClientConn if the file has changed.
+func (r *fileResolver) update() error {
+	b, err := os.ReadFile(r.filename)
+	if err != nil {
+		r.cc.ReportError(err)
+
+		return err
+	}
+
+	checksum := sha256.Sum256(b)
+
+	if checksum == r.checksum {
+		return nil
+	}
+
+	endpoints, err := parseEndpoints(r.filename, b)
+	if err != nil {
+		r.cc.ReportError(err)
+
+		return err
+	}
+
+	state := resolver.State{
+		Addresses: make([]resolver.Address, 0, len(endpoints)),
+	}
+
+	for _, ep := range endpoints {
+		state.Addresses = append(state.Addresses, resolver.Address{
+			Addr:       ep.Address,
+			ServerName: ep.ServerName,
+			Attributes: attributes.New(endpointInfoKey{}, &endpointInfo{ep}),
+		})
+	}
+
+	if err := r.cc.UpdateState(state); err != nil {
+		return err
+	}
+
+	r.checksum = checksum
+
+	logger.WithFields(logging.Fields{"filename": r.filename, "endpoints": len(endpoints)}).Info("Endpoints updated")
+
+	return nil
+}
+
+func (r *fileResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolve <- struct{}{}:
+	default:
+	}
+}
+
+func (r *fileResolver) Close() {
+	r.once.Do(func() { close(r.done) })
+}
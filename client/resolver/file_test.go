@@ -0,0 +1,119 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	want := []Endpoint{
+		{Address: "10.0.0.1:9191", ServerName: "a.example.com", Weight: 2, Metadata: map[string]string{"zone": "a"}},
+		{Address: "10.0.0.2:9191", ServerName: "10.0.0.2", Weight: 1},
+	}
+
+	tests := []struct {
+		filename string
+		content  string
+	}{
+		{"endpoints.json", `{"endpoints": [{"address": "10.0.0.1:9191", "server_name": "a.example.com", "weight": 2, "metadata": {"zone": "a"}}, {"address": "10.0.0.2:9191"}]}`},
+		{"endpoints.json", `[{"address": "10.0.0.1:9191", "server_name": "a.example.com", "weight": 2, "metadata": {"zone": "a"}}, {"address": "10.0.0.2:9191"}]`},
+		{"endpoints.ini", "; backends\n[endpoint]\naddress = 10.0.0.1:9191\nserver_name = a.example.com\nweight = 2\nmetadata.zone = a\n\n[endpoint]\naddress = \"10.0.0.2:9191\"\n"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseEndpoints(tt.filename, []byte(tt.content))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.filename, err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", tt.filename, got, want)
+		}
+	}
+
+	for _, content := range []string{"address = 10.0.0.1:9191", "[backend]", "[endpoint]\nweight = 1"} {
+		if _, err := parseEndpoints("endpoints.ini", []byte(content)); err == nil {
+			t.Errorf("%q: expected an error", content)
+		}
+	}
+}
+
+type testClientConn struct {
+	resolver.ClientConn
+
+	mu    sync.Mutex
+	state resolver.State
+}
+
+func (cc *testClientConn) UpdateState(s resolver.State) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.state = s
+
+	return nil
+}
+
+func (cc *testClientConn) ReportError(error) {}
+
+func (cc *testClientConn) addrs() []string {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	var addrs []string
+
+	for _, a := range cc.state.Addresses {
+		addrs = append(addrs, a.Addr)
+	}
+
+	return addrs
+}
+
+func TestFileResolverWatch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "endpoints.json")
+
+	if err := os.WriteFile(filename, []byte(`[{"address": "10.0.0.1:9191", "weight": 3}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := resolver.Target{}
+	target.URL.Path = filename
+
+	cc := new(testClientConn)
+
+	r, err := (&fileBuilder{}).Build(target, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if got := cc.addrs(); !reflect.DeepEqual(got, []string{"10.0.0.1:9191"}) {
+		t.Fatalf("unexpected addresses: %v", got)
+	}
+
+	if ep, ok := EndpointInfo(cc.state.Addresses[0]); !ok || ep.Weight != 3 {
+		t.Errorf("unexpected endpoint info: %+v", ep)
+	}
+
+	if name := cc.state.Addresses[0].ServerName; name != "10.0.0.1" {
+		t.Errorf("unexpected server name: %q", name)
+	}
+
+	if err := os.WriteFile(filename, []byte(`[{"address": "10.0.0.1:9191"}, {"address": "10.0.0.2:9191"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r.ResolveNow(resolver.ResolveNowOptions{})
+
+	for deadline := time.Now().Add(5 * time.Second); len(cc.addrs()) != 2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("addresses were not updated: %v", cc.addrs())
+		}
+	}
+}
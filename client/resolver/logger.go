@@ -0,0 +1,22 @@
+package resolver
+
+import (
+	"github.com/0xef53/go-grpc/logging"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var logger = logging.NewLogrusLogger(log.StandardLogger().WithField("subsystem", "resolver"))
+
+// SetLogger sets the global logger used by the package's entities.
+// It should be called during initialization, and it is strongly recommended
+// not to change it afterward.
+func SetLogger(entry *log.Entry) {
+	logger = logging.NewLogrusLogger(entry)
+}
+
+// SetLoggerAdapter is like [SetLogger] but accepts any [logging.Logger]
+// implementation, e.g. the one returned by [logging.NewSlogLogger].
+func SetLoggerAdapter(l logging.Logger) {
+	logger = l
+}
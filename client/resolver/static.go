@@ -0,0 +1,74 @@
+// Package resolver implements gRPC name resolvers used by the client package.
+//
+// The resolvers are registered by importing the package. It is imported by
+// the client package, so the schemes are available wherever it is used.
+package resolver
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// StaticScheme is the scheme of the static resolver.
+const StaticScheme = "static"
+
+func init() {
+	resolver.Register(&staticBuilder{})
+}
+
+// StaticTarget returns a target for a given list of endpoints ("host:port" pairs)
+// resolved by the static resolver, e.g. "static:///10.0.0.1:9191,10.0.0.2:9191".
+//
+// The host of each endpoint is used as its server name, i.e. as the authority
+// and the name for TLS verification of the connections to this endpoint.
+func StaticTarget(endpoints ...string) string {
+	return StaticScheme + ":///" + strings.Join(endpoints, ",")
+}
+
+type staticBuilder struct{}
+
+func (*staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	var addrs []resolver.Address
+
+	for _, ep := range strings.Split(target.Endpoint(), ",") {
+		if ep = strings.TrimSpace(ep); len(ep) > 0 {
+			addrs = append(addrs, resolver.Address{Addr: ep, ServerName: serverName(ep)})
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no endpoints in target: %s", target.URL.String())
+	}
+
+	if err := cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		return nil, err
+	}
+
+	return &staticResolver{}, nil
+}
+
+func (*staticBuilder) Scheme() string {
+	return StaticScheme
+}
+
+// serverName returns the host part of a given "host:port" pair.
+//
+// Without it, the authority of the connections would be the endpoint
+// part of the target with all its comma-separated endpoints.
+func serverName(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+
+	return hostport
+}
+
+// staticResolver does nothing, since the list of endpoints never changes.
+type staticResolver struct{}
+
+func (*staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (*staticResolver) Close() {}
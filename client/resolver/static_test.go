@@ -0,0 +1,104 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestStaticResolverServerName(t *testing.T) {
+	cc := new(testClientConn)
+
+	u, err := url.Parse(StaticTarget("a.example.com:9191", "10.0.0.2:9191", "[::1]:9191"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := (&staticBuilder{}).Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var names []string
+
+	for _, a := range cc.state.Addresses {
+		names = append(names, a.ServerName)
+	}
+
+	if want := []string{"a.example.com", "10.0.0.2", "::1"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got invalid server names: want %v, got %v", want, names)
+	}
+}
+
+// startAuthorityServer starts a server that records the authority and the local address
+// of each call to a given channel.
+func startAuthorityServer(t *testing.T, calls chan<- [2]string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		md, _ := grpc_metadata.FromIncomingContext(stream.Context())
+
+		calls <- [2]string{md.Get(":authority")[0], l.Addr().String()}
+
+		if err := stream.RecvMsg(new(emptypb.Empty)); err != nil {
+			return err
+		}
+
+		return stream.SendMsg(new(emptypb.Empty))
+	}))
+
+	go srv.Serve(l)
+
+	t.Cleanup(srv.Stop)
+
+	return l.Addr().String()
+}
+
+func TestStaticResolverBalancing(t *testing.T) {
+	calls := make(chan [2]string, 10)
+
+	endpoints := []string{startAuthorityServer(t, calls), startAuthorityServer(t, calls)}
+
+	conn, err := grpc.NewClient(
+		StaticTarget(endpoints...),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin": {}}]}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	seen := make(map[string]bool)
+
+	// Both endpoints should be reached once they are connected
+	for i := 0; i < 20 && len(seen) < 2; i++ {
+		if err := conn.Invoke(context.Background(), "/test.Svc/Get", new(emptypb.Empty), new(emptypb.Empty), grpc.WaitForReady(true)); err != nil {
+			t.Fatal(err)
+		}
+
+		c := <-calls
+
+		if host, _, _ := net.SplitHostPort(c[1]); c[0] != host {
+			t.Fatalf("got invalid authority %q for endpoint %s", c[0], c[1])
+		}
+
+		seen[c[1]] = true
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("the calls were not balanced across the endpoints: %v", seen)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"os"
+	"time"
 
 	grpcgateway "github.com/0xef53/go-grpc/gateway"
 	grpcserver "github.com/0xef53/go-grpc/server"
@@ -34,6 +36,12 @@ func NewServer(cfg *grpcserver.Config, tlsConfig *tls.Config, ui []grpc.UnarySer
 		return nil, fmt.Errorf("cannot create a new gRPC Gateway server: %w", err)
 	}
 
+	// Propagate drain mode changes (including those made via the admin server
+	// or by a signal) to the gateway
+	grpcServer.OnDrain(func(enabled bool, retryAfter time.Duration, buckets []string) {
+		gwServer.SetDrain(enabled, retryAfter, buckets...)
+	})
+
 	return &Server{
 		grpcServer: grpcServer,
 		gwServer:   gwServer,
@@ -50,6 +58,18 @@ func (s *Server) SetServiceBuckets(names ...string) {
 	s.gwServer.SetServiceBuckets(names...)
 }
 
+// SetDrain enables or disables the drain mode of both the gRPC server
+// and the gRPC Gateway server (see [grpcserver.Server.SetDrain] for details).
+func (s *Server) SetDrain(enabled bool, retryAfter time.Duration, buckets ...string) {
+	s.grpcServer.SetDrain(enabled, retryAfter, buckets...)
+}
+
+// ToggleDrainOnSignal toggles the drain mode of all served buckets each time
+// one of the given signals is received (see [grpcserver.Server.ToggleDrainOnSignal] for details).
+func (s *Server) ToggleDrainOnSignal(ctx context.Context, retryAfter time.Duration, sig ...os.Signal) {
+	s.grpcServer.ToggleDrainOnSignal(ctx, retryAfter, sig...)
+}
+
 // Start starts the composite server but does not wait for it to complete.
 //
 // Use the Wait() method to wait for the server to complete and then read its exit code.
@@ -0,0 +1,92 @@
+// Package zstd implements and registers the zstd compressor for gRPC.
+//
+// The compressor is registered by importing the package. It is imported
+// by the server and client packages, so the "zstd" name is available
+// wherever they are used.
+package zstd
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the name registered for the zstd compressor.
+const Name = "zstd"
+
+func init() {
+	c := &compressor{}
+
+	c.encoders.New = func() interface{} {
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+
+		return &writer{Encoder: enc, pool: &c.encoders}
+	}
+
+	encoding.RegisterCompressor(c)
+}
+
+type compressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+type writer struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *writer) Close() error {
+	defer w.pool.Put(w)
+
+	return w.Encoder.Close()
+}
+
+type reader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.Decoder.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r)
+	}
+
+	return n, err
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	z := c.encoders.Get().(*writer)
+
+	z.Encoder.Reset(w)
+
+	return z, nil
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	z, ok := c.decoders.Get().(*reader)
+	if !ok {
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+
+		return &reader{Decoder: dec, pool: &c.decoders}, nil
+	}
+
+	if err := z.Decoder.Reset(r); err != nil {
+		c.decoders.Put(z)
+
+		return nil, err
+	}
+
+	return z, nil
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
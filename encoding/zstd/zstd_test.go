@@ -0,0 +1,53 @@
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+)
+
+func TestCompressor(t *testing.T) {
+	c := encoding.GetCompressor(Name)
+	if c == nil {
+		t.Fatalf("compressor %q is not registered", Name)
+	}
+
+	payload := bytes.Repeat([]byte("some repeated payload "), 1000)
+
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+
+		w, err := c.Compress(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := w.Write(payload); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.Len() >= len(payload) {
+			t.Errorf("compressed size %d is not less than original size %d", buf.Len(), len(payload))
+		}
+
+		r, err := c.Decompress(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, payload) {
+			t.Fatalf("round %d: decompressed payload does not match the original", i)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the minimum size of the response body to be compressed.
+const gzipMinSize = 1024
+
+// defaultMaxRequestSize is the default maximum size of a decompressed request body.
+// It matches the default maximum size of a message received by the gRPC server.
+const defaultMaxRequestSize = 4 << 20
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+// gzipRequestHandler wraps a given handler to decode gzip-encoded request bodies.
+//
+// Requests whose decoded body exceeds maxSize bytes are answered with
+// "413 Request Entity Too Large". If maxSize is zero, [defaultMaxRequestSize] is used.
+func gzipRequestHandler(next http.Handler, maxSize int64) http.Handler {
+	if maxSize <= 0 {
+		maxSize = defaultMaxRequestSize
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "invalid gzip request body: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+		defer zr.Close()
+
+		// The body is decoded in advance to protect against decompression bombs
+		// and to answer with a proper status before the handler is called
+		b, err := io.ReadAll(io.LimitReader(zr, maxSize+1))
+		if err != nil {
+			http.Error(w, "invalid gzip request body: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		if int64(len(b)) > maxSize {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(b))
+
+		r.Header.Del("Content-Encoding")
+		r.Header.Set("Content-Length", strconv.Itoa(len(b)))
+		r.ContentLength = int64(len(b))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// gzipResponseHandler wraps a given handler to gzip-encode responses
+// for clients that accept it.
+func gzipResponseHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(r) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether the client accepts gzip-encoded responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, v := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(v, ";")
+
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+
+		// "gzip;q=0" means "not acceptable"
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && q == 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// gzipResponseWriter buffers the beginning of the response to decide whether
+// it is worth compressing. Responses smaller than gzipMinSize, responses
+// without body and already encoded responses are written as is.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool // the header was written to the underlying writer
+	buf         []byte
+	zw          *gzip.Writer
+	passthrough bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	switch {
+	case w.zw != nil:
+		return w.zw.Write(b)
+	case w.passthrough:
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+
+	if len(w.buf) >= gzipMinSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// Flush starts sending the response. It is used by the streaming handlers.
+func (w *gzipResponseWriter) Flush() {
+	if w.zw == nil && !w.passthrough {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+
+		if err := w.start(len(w.buf) > 0); err != nil {
+			return
+		}
+	}
+
+	if w.zw != nil {
+		w.zw.Flush()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements the [http.Hijacker] interface.
+func (w *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, errors.New("http.Hijacker is not implemented")
+}
+
+// Unwrap is used by [http.ResponseController].
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the header and the buffered data to the underlying writer,
+// compressing them if possible.
+func (w *gzipResponseWriter) start(compress bool) error {
+	h := w.ResponseWriter.Header()
+
+	if len(h.Get("Content-Encoding")) > 0 || w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		compress = false
+	}
+
+	if compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+
+		w.zw = gzipWriters.Get().(*gzip.Writer)
+		w.zw.Reset(w.ResponseWriter)
+	} else {
+		w.passthrough = true
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	w.wroteHeader = true
+
+	buf := w.buf
+	w.buf = nil
+
+	if len(buf) == 0 {
+		return nil
+	}
+
+	if w.zw != nil {
+		_, err := w.zw.Write(buf)
+
+		return err
+	}
+
+	_, err := w.ResponseWriter.Write(buf)
+
+	return err
+}
+
+// close finishes the response.
+func (w *gzipResponseWriter) close() {
+	if !w.wroteHeader {
+		if w.status == 0 {
+			// Nothing was written by the handler
+			return
+		}
+
+		// Too small to compress
+		w.start(false)
+	}
+
+	if w.zw != nil {
+		w.zw.Close()
+
+		gzipWriters.Put(w.zw)
+
+		w.zw = nil
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipResponseHandler(t *testing.T) {
+	large := strings.Repeat("a", 2*gzipMinSize)
+
+	h := gzipResponseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+
+		io.WriteString(w, r.URL.Query().Get("body"))
+	}))
+
+	tests := []struct {
+		acceptEncoding string
+		body           string
+		wantGzip       bool
+	}{
+		{"gzip, deflate", large, true},
+		{"br;q=1.0, gzip;q=0.5", large, true},
+		{"gzip;q=0", large, false},
+		{"", large, false},
+		{"gzip", "small", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/?body="+tt.body, nil)
+		r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("%q: unexpected status: got %d", tt.acceptEncoding, w.Code)
+		}
+
+		body := w.Body.Bytes()
+
+		if got := w.Header().Get("Content-Encoding") == "gzip"; got != tt.wantGzip {
+			t.Fatalf("%q: gzip encoding: got %t, want %t", tt.acceptEncoding, got, tt.wantGzip)
+		}
+
+		if tt.wantGzip {
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if body, err = io.ReadAll(zr); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if string(body) != tt.body {
+			t.Errorf("%q: unexpected body of length %d", tt.acceptEncoding, len(body))
+		}
+	}
+}
+
+func TestGzipRequestHandler(t *testing.T) {
+	gzipBody := func(s string) *bytes.Buffer {
+		var buf bytes.Buffer
+
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+
+		return &buf
+	}
+
+	var got string
+
+	h := gzipRequestHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		got = string(b)
+	}), 32)
+
+	tests := []struct {
+		body       io.Reader
+		wantStatus int
+		wantBody   string
+	}{
+		{gzipBody(`{"name":"test"}`), http.StatusOK, `{"name":"test"}`},
+		{gzipBody(strings.Repeat("a", 32)), http.StatusOK, strings.Repeat("a", 32)},
+		// Exceeds the limit when decoded
+		{gzipBody(strings.Repeat("a", 1024)), http.StatusRequestEntityTooLarge, ""},
+		{strings.NewReader("not gzip"), http.StatusBadRequest, ""},
+	}
+
+	for idx, tt := range tests {
+		got = ""
+
+		r := httptest.NewRequest(http.MethodPost, "/", tt.body)
+		r.Header.Set("Content-Encoding", "gzip")
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("idx == %d: got status %d, want %d", idx, w.Code, tt.wantStatus)
+		}
+
+		if got != tt.wantBody {
+			t.Errorf("idx == %d: unexpected request body: %q", idx, got)
+		}
+	}
+}
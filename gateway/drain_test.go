@@ -0,0 +1,49 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	grpcserver "github.com/0xef53/go-grpc/server"
+)
+
+func TestDrainResponse(t *testing.T) {
+	cfg := &grpcserver.Config{Bindings: []string{"127.0.0.1"}, Port: 1, GatewayPort: 2, GRPCSocketPath: "/run/test.sock"}
+
+	s, err := NewServer(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetServiceBuckets("a", "b")
+
+	tests := []struct {
+		enabled    bool
+		buckets    []string
+		wantStatus int
+		wantRetry  string
+	}{
+		{true, []string{"a", "b"}, http.StatusServiceUnavailable, "2"},
+		// Only some of the served buckets are drained, so the gRPC server decides
+		{true, []string{"a"}, http.StatusNotFound, ""},
+		{false, []string{"a", "b"}, http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		s.SetDrain(tt.enabled, 2*time.Second, tt.buckets...)
+
+		w := httptest.NewRecorder()
+
+		s.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/unknown", nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%t %v: got status %d, want %d", tt.enabled, tt.buckets, w.Code, tt.wantStatus)
+		}
+
+		if got := w.Header().Get("Retry-After"); got != tt.wantRetry {
+			t.Errorf("%t %v: got Retry-After %q, want %q", tt.enabled, tt.buckets, got, tt.wantRetry)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/requestid"
+)
+
+// maxRequestIDLength limits the length of the request IDs accepted from clients.
+const maxRequestIDLength = 256
+
+// requestIDHandler wraps a given handler to assign a request ID to each HTTP request.
+//
+// The ID is taken from the request header (see [requestid.Header]) or generated
+// if the header is missing or invalid. It is passed to the gRPC server as
+// the request ID metadata, returned in the response header and logged
+// with the request properties when the request is completed.
+func requestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		header := requestid.Header()
+
+		reqID := r.Header.Get(header)
+
+		if validRequestID(reqID) {
+			reqID = requestid.Compact(reqID)
+		} else {
+			reqID = requestid.New()
+		}
+
+		// The gateway mux passes the header as the request ID metadata
+		r.Header.Set(header, reqID)
+
+		w.Header().Set(header, reqID)
+
+		entry := logger.WithFields(logging.Fields{
+			"request.uid":    reqID,
+			"http.method":    r.Method,
+			"http.path":      r.URL.Path,
+			"peer.address":   r.RemoteAddr,
+			"http.proto":     r.Proto,
+			"http.useragent": r.UserAgent(),
+		})
+
+		rw := &statusResponseWriter{ResponseWriter: w}
+
+		next.ServeHTTP(rw, r.WithContext(logging.ToContext(r.Context(), entry)))
+
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+
+		entry = entry.WithFields(logging.Fields{
+			"http.status":  rw.status,
+			"http.bytes":   rw.bytes,
+			"http.time_ms": float64(time.Since(start).Nanoseconds()/1000) / 1000,
+		})
+
+		switch {
+		case rw.status >= http.StatusInternalServerError:
+			entry.Error("HTTP request completed")
+		case rw.status >= http.StatusBadRequest:
+			entry.Warn("HTTP request completed")
+		default:
+			entry.Info("HTTP request completed")
+		}
+	})
+}
+
+// validRequestID reports whether a given request ID received from a client
+// can be used as is: it is not empty, not too long and contains only
+// printable ASCII characters.
+func validRequestID(s string) bool {
+	if len(s) == 0 || len(s) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7E {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statusResponseWriter records the status code and the number of bytes written.
+type statusResponseWriter struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int64
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+// Flush implements the [http.Flusher] interface.
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements the [http.Hijacker] interface.
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, errors.New("http.Hijacker is not implemented")
+}
+
+// Unwrap is used by [http.ResponseController].
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDHandler(t *testing.T) {
+	var got string
+
+	h := requestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Request-Id")
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	tests := []struct {
+		header   string
+		preserve bool
+	}{
+		{"ABCDEF", true},
+		{"ROOT:PARENT", true},
+		{"", false},
+		{"invalid id", false},
+		{strings.Repeat("A", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		if len(tt.header) > 0 {
+			r.Header.Set("X-Request-Id", tt.header)
+		}
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if len(got) == 0 {
+			t.Fatalf("%q: request ID is not set", tt.header)
+		}
+
+		if (got == tt.header) != tt.preserve {
+			t.Errorf("%q: unexpected request ID: %q", tt.header, got)
+		}
+
+		if resp := w.Header().Get("X-Request-Id"); resp != got {
+			t.Errorf("%q: response header %q does not match the request ID %q", tt.header, resp, got)
+		}
+
+		if w.Code != http.StatusAccepted {
+			t.Errorf("%q: unexpected status: %d", tt.header, w.Code)
+		}
+	}
+}
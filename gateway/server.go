@@ -5,27 +5,44 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/0xef53/go-grpc/client"
 	"github.com/0xef53/go-grpc/client/interceptors"
+	"github.com/0xef53/go-grpc/client/resolver"
 	"github.com/0xef53/go-grpc/gateway/utils"
+	"github.com/0xef53/go-grpc/logging"
 	grpcserver "github.com/0xef53/go-grpc/server"
+	"github.com/0xef53/go-grpc/status"
+	grpcutils "github.com/0xef53/go-grpc/utils"
 
 	grpc_runtime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/protoadapt"
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 )
 
-var logger = log.StandardLogger().WithField("subsystem", "gateway")
+var logger = logging.NewLogrusLogger(log.StandardLogger().WithField("subsystem", "gateway"))
 
 // SetLogger sets the global logger used by the package's entities.
 // It should be called during initialization, and it is strongly recommended
 // not to change it afterward.
 func SetLogger(entry *log.Entry) {
-	logger = entry
+	logger = logging.NewLogrusLogger(entry)
+}
+
+// SetLoggerAdapter is like [SetLogger] but accepts any [logging.Logger]
+// implementation, e.g. the one returned by [logging.NewSlogLogger].
+func SetLoggerAdapter(l logging.Logger) {
+	logger = l
 }
 
 // Server represents a gRPC-Gateway server that bridges gRPC services with HTTP/REST clients.
@@ -39,17 +56,29 @@ type Server struct {
 
 	buckets []string
 
+	drain struct {
+		sync.RWMutex
+		enabled    bool
+		retryAfter time.Duration
+	}
+
 	group *errgroup.Group
 }
 
 // NewServer creates and configures a new gRPC Gateway server.
 //
 // Communication with the gRPC server takes place via a unix socket
-// (GRPCSocketPath field in grpcserver.Config structure).
+// (GRPCSocketPath field in grpcserver.Config structure) or via the target
+// specified in the GatewayBackend field. In the latter case, the requests
+// are balanced across the resolved endpoints using the round-robin policy,
+// weighted if the endpoints are read from a file.
 //
 // When configuring the connection, аn unary client logging interceptor are used
 // (see ... for details).
-func NewServer(cfg *grpcserver.Config, tlsConfig *tls.Config) (*Server, error) {
+//
+// Additional options are passed to the gateway mux (see [utils.NewGatewayMux]),
+// e.g. to replace the default error handler.
+func NewServer(cfg *grpcserver.Config, tlsConfig *tls.Config, muxOpts ...grpc_runtime.ServeMuxOption) (*Server, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -58,7 +87,7 @@ func NewServer(cfg *grpcserver.Config, tlsConfig *tls.Config) (*Server, error) {
 		config:     cfg,
 		tlsConfig:  tlsConfig,
 		httpServer: new(http.Server),
-		mux:        utils.NewGatewayMux(),
+		mux:        utils.NewGatewayMux(muxOpts...),
 		dialOpts:   make([]grpc.DialOption, 0, 2),
 		group:      new(errgroup.Group),
 	}
@@ -69,7 +98,24 @@ func NewServer(cfg *grpcserver.Config, tlsConfig *tls.Config) (*Server, error) {
 		s.dialOpts = append(s.dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	}
 
-	s.dialOpts = append(s.dialOpts, grpc.WithChainUnaryInterceptor(interceptors.WithRequestLogging(logger)))
+	s.dialOpts = append(s.dialOpts, grpc.WithChainUnaryInterceptor(interceptors.WithRequestLoggingAdapter(logger)))
+
+	if len(cfg.GatewayBackend) == 0 {
+		// The local server trusts the client addresses forwarded
+		// by the gateway only with the token (see [grpcutils.ClientAddr])
+		s.dialOpts = append(s.dialOpts,
+			grpc.WithChainUnaryInterceptor(gatewayTokenInterceptor),
+			grpc.WithChainStreamInterceptor(gatewayTokenStreamInterceptor),
+		)
+	} else {
+		policy := client.RoundRobin
+
+		if strings.HasPrefix(cfg.GatewayBackend, resolver.FileScheme+":") {
+			policy = client.WeightedRoundRobin
+		}
+
+		s.dialOpts = append(s.dialOpts, client.WithLoadBalancing(policy))
+	}
 
 	s.SetHTTPHandler(func(m *grpc_runtime.ServeMux) http.Handler {
 		mux := http.NewServeMux()
@@ -82,6 +128,14 @@ func NewServer(cfg *grpcserver.Config, tlsConfig *tls.Config) (*Server, error) {
 	return s, nil
 }
 
+func gatewayTokenInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(grpcutils.WithGatewayToken(ctx), method, req, reply, cc, opts...)
+}
+
+func gatewayTokenStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(grpcutils.WithGatewayToken(ctx), desc, cc, method, opts...)
+}
+
 // SetServiceBuckets sets a list of buckets used by the gRPC Gateway server to discover
 // and register target services for serving.
 //
@@ -93,17 +147,95 @@ func (s *Server) SetServiceBuckets(names ...string) {
 // SetHTTPHandler allows replacing the default HTTP handler with a custom one.
 //
 // The handler should not change after the server starts.
+//
+// Each request is assigned a request ID, which is passed to the gRPC server
+// and returned in the response header ("X-Request-Id" by default).
+// Gzip-encoded request bodies are always decoded up to the GatewayMaxRequestSize
+// field of the config. Responses are gzip-encoded if the GatewayCompression field
+// of the config is set.
 func (s *Server) SetHTTPHandler(fn func(m *grpc_runtime.ServeMux) http.Handler) {
-	s.httpServer.Handler = fn(s.mux)
+	h := gzipRequestHandler(fn(s.mux), s.config.GatewayMaxRequestSize)
+
+	if s.config.GatewayCompression {
+		h = gzipResponseHandler(h)
+	}
+
+	s.httpServer.Handler = requestIDHandler(s.drainHandler(h))
+}
+
+// SetDrain enables or disables the drain mode.
+//
+// If all buckets served by the gateway are in the given list, the gateway answers
+// all new requests with "503 Service Unavailable" and the "Retry-After" header.
+// Otherwise, requests are passed to the gRPC server, which rejects the calls
+// of the drained services with the Unavailable code.
+func (s *Server) SetDrain(enabled bool, retryAfter time.Duration, buckets ...string) {
+	own := s.buckets
+
+	if len(own) == 0 {
+		own = []string{"default"}
+	}
+
+	all := true
+
+	for _, b := range own {
+		if !slices.Contains(buckets, b) {
+			all = false
+
+			break
+		}
+	}
+
+	s.drain.Lock()
+	defer s.drain.Unlock()
+
+	s.drain.enabled = enabled && all
+	s.drain.retryAfter = retryAfter
+}
+
+// drainHandler wraps a given handler to reject requests in drain mode.
+func (s *Server) drainHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.drain.RLock()
+
+		enabled, retryAfter := s.drain.enabled, s.drain.retryAfter
+
+		s.drain.RUnlock()
+
+		if enabled {
+			var details []protoadapt.MessageV1
+
+			if retryAfter > 0 {
+				details = append(details, status.RetryInfo(retryAfter))
+			}
+
+			err := status.Error(r.Context(), grpc_codes.Unavailable, "server is in drain mode", details...)
+
+			// Render the error in the same way as the errors from the gRPC server
+			_, marshaler := grpc_runtime.MarshalerForRequest(s.mux, r)
+
+			grpc_runtime.HTTPError(r.Context(), s.mux, marshaler, w, r, err)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
 }
 
 // listenAndServe starts the gRPC Gateway server and serves services corresponding
 // to the given list of buckets.
 func (s *Server) listenAndServe(ctx context.Context) error {
+	backend := s.config.GatewayBackend
+
+	if len(backend) == 0 {
+		backend = fmt.Sprintf("unix:%s", s.config.GRPCSocketPath)
+	}
+
 	for _, svc := range grpcserver.Services(s.buckets...) {
 		logger.Info("Registering GW service: ", svc.Name())
 
-		svc.RegisterGW(s.mux, fmt.Sprintf("unix:%s", s.config.GRPCSocketPath), s.dialOpts)
+		svc.RegisterGW(s.mux, backend, s.dialOpts)
 	}
 
 	listeners, err := s.config.GetGatewayListeners()
@@ -131,14 +263,14 @@ func (s *Server) listenAndServe(ctx context.Context) error {
 		listener := l
 
 		group.Go(func() error {
-			logger.WithFields(log.Fields{"addr": listener.Addr().String()}).Info("Starting GRPC Gateway server")
+			logger.WithFields(logging.Fields{"addr": listener.Addr().String()}).Info("Starting GRPC Gateway server")
 
 			if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 				// Error starting or closing listener
 				return err
 			}
 
-			logger.WithFields(log.Fields{"addr": listener.Addr().String()}).Info("GRPC Gateway server stopped")
+			logger.WithFields(logging.Fields{"addr": listener.Addr().String()}).Info("GRPC Gateway server stopped")
 
 			return nil
 		})
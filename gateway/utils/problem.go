@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/0xef53/go-grpc/requestid"
+	"github.com/0xef53/go-grpc/status"
+
+	grpc_runtime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details documents.
+const ProblemContentType = "application/problem+json"
+
+// Problem represents an RFC 7807 problem details document extended with
+// the gRPC status properties.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Code      string `json:"grpc_code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	Reason   string            `json:"reason,omitempty"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	RetryAfter      float64          `json:"retry_after,omitempty"`
+	FieldViolations []FieldViolation `json:"field_violations,omitempty"`
+
+	// Details contains the error details that have no flattened representation
+	// marshaled with the same protojson options as the mux's marshaler.
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// FieldViolation is a flattened representation of [errdetails.BadRequest_FieldViolation].
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// ProblemOption is a common type for optional parameters of [NewProblemErrorHandler].
+type ProblemOption func(*problemOptions)
+
+type problemOptions struct {
+	typeFunc func(grpc_codes.Code) string
+}
+
+// WithProblemType sets a function that returns the "type" URI of the problem
+// for a given gRPC code. By default, "about:blank" is used.
+func WithProblemType(fn func(grpc_codes.Code) string) ProblemOption {
+	return func(o *problemOptions) {
+		o.typeFunc = fn
+	}
+}
+
+// NewProblemErrorHandler returns a [grpc_runtime.ErrorHandlerFunc] that renders errors
+// as RFC 7807 "application/problem+json" documents.
+//
+// The document contains the gRPC code, the mapped HTTP status, the request ID and
+// flattened error details (see [status] package). Details are marshaled using
+// the protojson options of the mux's marshaler.
+func NewProblemErrorHandler(opts ...ProblemOption) grpc_runtime.ErrorHandlerFunc {
+	o := &problemOptions{
+		typeFunc: func(grpc_codes.Code) string { return "about:blank" },
+	}
+
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	return func(ctx context.Context, mux *grpc_runtime.ServeMux, marshaler grpc_runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		httpStatus := 0
+
+		var customStatus *grpc_runtime.HTTPStatusError
+
+		if errors.As(err, &customStatus) {
+			err = customStatus.Err
+			httpStatus = customStatus.HTTPStatus
+		}
+
+		st := grpc_status.Convert(err)
+
+		if httpStatus == 0 {
+			httpStatus = grpc_runtime.HTTPStatusFromCode(st.Code())
+		}
+
+		problem := Problem{
+			Type:     o.typeFunc(st.Code()),
+			Title:    http.StatusText(httpStatus),
+			Status:   httpStatus,
+			Detail:   st.Message(),
+			Instance: r.URL.Path,
+			Code:     st.Code().String(),
+		}
+
+		problem.RequestID = problemRequestID(ctx, r, err)
+
+		flattenDetails(&problem, st, jsonMarshaler(marshaler))
+
+		w.Header().Del("Trailer")
+		w.Header().Del("Transfer-Encoding")
+
+		if md, ok := grpc_runtime.ServerMetadataFromContext(ctx); ok {
+			for k, vs := range md.HeaderMD {
+				for _, v := range vs {
+					w.Header().Add(fmt.Sprintf("%s%s", grpc_runtime.MetadataHeaderPrefix, k), v)
+				}
+			}
+		}
+
+		if st.Code() == grpc_codes.Unauthenticated {
+			w.Header().Set("WWW-Authenticate", st.Message())
+		}
+
+		if problem.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(problem.RetryAfter))))
+		}
+
+		if len(problem.RequestID) > 0 {
+			w.Header().Set(requestid.Header(), problem.RequestID)
+		}
+
+		b, err := json.Marshal(&problem)
+		if err != nil {
+			grpclog.Errorf("Failed to marshal problem details %q: %v", st, err)
+
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", ProblemContentType)
+
+		w.WriteHeader(httpStatus)
+
+		if _, err := w.Write(b); err != nil {
+			grpclog.Errorf("Failed to write response: %v", err)
+		}
+	}
+}
+
+// problemRequestID returns the request ID from the error details,
+// the gRPC response header or the HTTP request header.
+func problemRequestID(ctx context.Context, r *http.Request, err error) string {
+	if reqID, ok := status.RequestID(err); ok {
+		return reqID
+	}
+
+	if md, ok := grpc_runtime.ServerMetadataFromContext(ctx); ok {
+		if v := md.HeaderMD.Get(requestid.MetadataKey()); len(v) > 0 {
+			return v[0]
+		}
+	}
+
+	return r.Header.Get(requestid.Header())
+}
+
+// flattenDetails moves the known error details to the top-level properties of the problem.
+// Unknown details are marshaled as is.
+func flattenDetails(p *Problem, st *grpc_status.Status, m *grpc_runtime.JSONPb) {
+	for _, d := range st.Details() {
+		switch v := d.(type) {
+		case *errdetails.RequestInfo:
+			// Already handled
+		case *errdetails.ErrorInfo:
+			p.Reason = v.Reason
+			p.Domain = v.Domain
+			p.Metadata = v.Metadata
+		case *errdetails.RetryInfo:
+			p.RetryAfter = v.GetRetryDelay().AsDuration().Seconds()
+		case *errdetails.BadRequest:
+			for _, fv := range v.FieldViolations {
+				p.FieldViolations = append(p.FieldViolations, FieldViolation{Field: fv.Field, Description: fv.Description})
+			}
+		case proto.Message:
+			// Wrap into Any to keep the type URL in the output
+			if a, err := anypb.New(v); err == nil {
+				if b, err := m.MarshalOptions.Marshal(a); err == nil {
+					p.Details = append(p.Details, b)
+				}
+			}
+		}
+	}
+}
+
+// jsonMarshaler returns the given marshaler if it is [grpc_runtime.JSONPb],
+// otherwise the default gateway marshaler.
+func jsonMarshaler(m grpc_runtime.Marshaler) *grpc_runtime.JSONPb {
+	if v, ok := m.(*grpc_runtime.JSONPb); ok {
+		return v
+	}
+
+	return newJSONMarshaler()
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/0xef53/go-grpc/status"
+
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_metadata "google.golang.org/grpc/metadata"
+)
+
+func TestProblemErrorHandler(t *testing.T) {
+	ctx := grpc_metadata.NewIncomingContext(context.Background(), grpc_metadata.Pairs("request-id", "ABCDEF"))
+
+	err := status.Error(ctx, grpc_codes.InvalidArgument, "invalid request",
+		status.BadRequest(status.FieldViolation("name", "must not be empty")),
+		status.RetryInfo(1500*time.Millisecond),
+		status.LocalizedMessage("en-US", "Invalid request"),
+	)
+
+	mux := NewGatewayMux()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/v1/users", nil)
+
+	NewProblemErrorHandler()(context.Background(), mux, newJSONMarshaler(), w, r, err)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got invalid HTTP status: %d", w.Code)
+	}
+
+	if v := w.Header().Get("Content-Type"); v != ProblemContentType {
+		t.Fatalf("got invalid content type: %q", v)
+	}
+
+	if v := w.Header().Get("Retry-After"); v != "2" {
+		t.Fatalf("got invalid Retry-After header: %q", v)
+	}
+
+	var p Problem
+
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("cannot unmarshal problem: %s", err)
+	}
+
+	if p.Code != "InvalidArgument" || p.RequestID != "ABCDEF" || p.Detail != "invalid request" {
+		t.Fatalf("got invalid problem: %+v", p)
+	}
+
+	if len(p.FieldViolations) != 1 || p.FieldViolations[0].Field != "name" {
+		t.Fatalf("got invalid field violations: %+v", p.FieldViolations)
+	}
+
+	if len(p.Details) != 1 {
+		t.Fatalf("got invalid number of details: %d", len(p.Details))
+	}
+}
+
+func TestProblemErrorHandlerPlainError(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+
+	r.Header.Set("X-Request-Id", "QWERTY")
+
+	NewProblemErrorHandler()(context.Background(), NewGatewayMux(), newJSONMarshaler(), w, r, errors.New("unexpected"))
+
+	var p Problem
+
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("cannot unmarshal problem: %s", err)
+	}
+
+	if w.Code != http.StatusInternalServerError || p.Code != "Unknown" || p.RequestID != "QWERTY" {
+		t.Fatalf("got invalid problem (status == %d): %+v", w.Code, p)
+	}
+}
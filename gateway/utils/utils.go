@@ -1,10 +1,16 @@
 package utils
 
 import (
+	"context"
+	"net/http"
+	"net/textproto"
 	"strings"
 
+	"github.com/0xef53/go-grpc/requestid"
+
 	grpc_runtime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 // NewGatewayMux returns a new gRPC-Gateway ServeMux with custom serialization
@@ -12,26 +18,52 @@ import (
 //
 // Among other things, it forwards all headers starting with "X-"
 // by converting them to lowercase and removing the "X-" prefix.
-func NewGatewayMux() *grpc_runtime.ServeMux {
-	gwMux := grpc_runtime.NewServeMux(
-		grpc_runtime.WithMarshalerOption(
-			grpc_runtime.MIMEWildcard,
-			&grpc_runtime.JSONPb{
-				MarshalOptions: protojson.MarshalOptions{
-					UseProtoNames:   true,
-					EmitUnpopulated: true,
-				},
-				UnmarshalOptions: protojson.UnmarshalOptions{},
-			},
-		),
+// The request ID header (see [requestid.Header]) is passed as the request ID metadata
+// and the request ID returned by the gRPC server is set in the response header.
+// Errors are rendered as RFC 7807 problem details (see [NewProblemErrorHandler]).
+//
+// Additional options are applied after the default ones and can override them.
+func NewGatewayMux(opts ...grpc_runtime.ServeMuxOption) *grpc_runtime.ServeMux {
+	defaultOpts := []grpc_runtime.ServeMuxOption{
+		grpc_runtime.WithMarshalerOption(grpc_runtime.MIMEWildcard, newJSONMarshaler()),
 		// Forward all X-Headers
 		grpc_runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			if key == textproto.CanonicalMIMEHeaderKey(requestid.Header()) {
+				return requestid.MetadataKey(), true
+			}
 			if strings.HasPrefix(key, "X-") {
 				return strings.ToLower(strings.TrimPrefix(key, "X-")), true
 			}
 			return grpc_runtime.DefaultHeaderMatcher(key)
 		}),
-	)
+		grpc_runtime.WithErrorHandler(NewProblemErrorHandler()),
+		grpc_runtime.WithForwardResponseOption(forwardRequestID),
+	}
+
+	gwMux := grpc_runtime.NewServeMux(append(defaultOpts, opts...)...)
 
 	return gwMux
 }
+
+// forwardRequestID sets the request ID header of the response
+// from the gRPC response header.
+func forwardRequestID(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+	if md, ok := grpc_runtime.ServerMetadataFromContext(ctx); ok {
+		if v := md.HeaderMD.Get(requestid.MetadataKey()); len(v) > 0 {
+			w.Header().Set(requestid.Header(), v[0])
+		}
+	}
+
+	return nil
+}
+
+// newJSONMarshaler returns the marshaler used by the gateway mux.
+func newJSONMarshaler() *grpc_runtime.JSONPb {
+	return &grpc_runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{},
+	}
+}
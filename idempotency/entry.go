@@ -0,0 +1,115 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// NewEntry returns a new [Entry] containing a given response or error
+// that expires after ttl.
+func NewEntry(resp interface{}, err error, ttl time.Duration) (*Entry, error) {
+	e := Entry{
+		Expires: time.Now().Add(ttl),
+	}
+
+	if err != nil {
+		b, err := proto.Marshal(grpc_status.Convert(err).Proto())
+		if err != nil {
+			return nil, err
+		}
+
+		e.Status = b
+
+		return &e, nil
+	}
+
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("unsupported response type: %T", resp)
+	}
+
+	a, err := anypb.New(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := proto.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Response = b
+
+	return &e, nil
+}
+
+// Result decodes the stored response or error.
+//
+// The response message type must be known to the global protobuf registry.
+func (e *Entry) Result() (proto.Message, error) {
+	if len(e.Status) > 0 {
+		st := new(spb.Status)
+
+		if err := proto.Unmarshal(e.Status, st); err != nil {
+			return nil, err
+		}
+
+		return nil, grpc_status.FromProto(st).Err()
+	}
+
+	a := new(anypb.Any)
+
+	if err := proto.Unmarshal(e.Response, a); err != nil {
+		return nil, err
+	}
+
+	return a.UnmarshalNew()
+}
+
+// Matches reports whether the entry belongs to a request with a given hash.
+// Entries stored without a hash match any request.
+func (e *Entry) Matches(hash []byte) bool {
+	return len(e.RequestHash) == 0 || bytes.Equal(e.RequestHash, hash)
+}
+
+// RequestHash returns the SHA-256 hash of the deterministically marshaled request.
+func RequestHash(req interface{}) ([]byte, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("unsupported request type: %T", req)
+	}
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return sum[:], nil
+}
+
+// IsTransient reports whether a given error is a transient failure
+// that should not be stored, so that a retry executes the request again.
+func IsTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	switch grpc_status.Code(err) {
+	case grpc_codes.Unavailable, grpc_codes.DeadlineExceeded, grpc_codes.Canceled, grpc_codes.ResourceExhausted, grpc_codes.Aborted:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileStore is a [Store] that keeps each entry in a separate file
+// in a given directory. File names are derived from the SHA-256 hash of the key.
+//
+// Expired entries are removed when they are accessed or by calling Cleanup.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a new [FileStore] using a given directory.
+// The directory is created if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the entry stored under a given key.
+func (s *FileStore) Get(key string) (*Entry, bool, error) {
+	b, err := os.ReadFile(s.filename(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var e Entry
+
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, false, err
+	}
+
+	if e.Expired(time.Now()) {
+		os.Remove(s.filename(key))
+
+		return nil, false, nil
+	}
+
+	return &e, true, nil
+}
+
+// Set stores a given entry under a given key.
+//
+// The entry is written to a temporary file first and then renamed,
+// so that readers never see a partially written entry.
+func (s *FileStore) Set(key string, e *Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	fd, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := fd.Write(b); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+
+		return err
+	}
+
+	if err := fd.Close(); err != nil {
+		os.Remove(fd.Name())
+
+		return err
+	}
+
+	return os.Rename(fd.Name(), s.filename(key))
+}
+
+// Cleanup removes all expired entries from the directory.
+func (s *FileStore) Cleanup() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, fname := range files {
+		b, err := os.ReadFile(fname)
+		if err != nil {
+			continue
+		}
+
+		var e Entry
+
+		if err := json.Unmarshal(b, &e); err != nil || e.Expired(now) {
+			os.Remove(fname)
+		}
+	}
+
+	return nil
+}
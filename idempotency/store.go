@@ -0,0 +1,118 @@
+package idempotency
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Entry represents the stored result of the first execution of a request.
+type Entry struct {
+	// Response is a marshaled [anypb.Any] containing the response message.
+	Response []byte `json:"response,omitempty"`
+
+	// Status is a marshaled [spb.Status] containing the returned error.
+	Status []byte `json:"status,omitempty"`
+
+	// RequestHash is the hash of the request the result belongs to (see [RequestHash]).
+	RequestHash []byte `json:"request_hash,omitempty"`
+
+	// Expires is the time after which the entry is no longer valid.
+	Expires time.Time `json:"expires"`
+}
+
+// Expired reports whether the entry is expired at a given time.
+func (e *Entry) Expired(now time.Time) bool {
+	return !e.Expires.After(now)
+}
+
+// Store is a common interface for storages of request execution results.
+//
+// Get should not return expired entries.
+type Store interface {
+	Get(key string) (*Entry, bool, error)
+	Set(key string, e *Entry) error
+}
+
+// MemoryStore is an in-memory [Store] that evicts the least recently used
+// entries when the capacity is exceeded.
+type MemoryStore struct {
+	mu sync.Mutex
+
+	capacity int
+
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewMemoryStore returns a new [MemoryStore] holding up to capacity entries.
+// If capacity is zero, the number of entries is not limited.
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored under a given key.
+func (s *MemoryStore) Get(key string) (*Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	item := el.Value.(*memoryItem)
+
+	if item.entry.Expired(time.Now()) {
+		s.ll.Remove(el)
+		delete(s.items, key)
+
+		return nil, false, nil
+	}
+
+	s.ll.MoveToFront(el)
+
+	return item.entry, true, nil
+}
+
+// Set stores a given entry under a given key.
+func (s *MemoryStore) Set(key string, e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		el.Value.(*memoryItem).entry = e
+
+		s.ll.MoveToFront(el)
+
+		return nil
+	}
+
+	s.items[key] = s.ll.PushFront(&memoryItem{key: key, entry: e})
+
+	if s.capacity > 0 && s.ll.Len() > s.capacity {
+		if el := s.ll.Back(); el != nil {
+			s.ll.Remove(el)
+			delete(s.items, el.Value.(*memoryItem).key)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of stored entries.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ll.Len()
+}
@@ -0,0 +1,71 @@
+package idempotency
+
+import (
+	"testing"
+	"time"
+
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMemoryStoreEviction(t *testing.T) {
+	s := NewMemoryStore(2)
+
+	for _, key := range []string{"a", "b", "c"} {
+		s.Set(key, &Entry{Expires: time.Now().Add(time.Minute)})
+	}
+
+	if _, ok, _ := s.Get("a"); ok {
+		t.Fatalf("the least recently used entry was not evicted")
+	}
+
+	if s.Len() != 2 {
+		t.Fatalf("got invalid number of entries: %d", s.Len())
+	}
+
+	s.Set("d", &Entry{Expires: time.Now().Add(-time.Second)})
+
+	if _, ok, _ := s.Get("d"); ok {
+		t.Fatalf("got expired entry")
+	}
+}
+
+func TestFileStoreResult(t *testing.T) {
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create file store: %s", err)
+	}
+
+	want := wrapperspb.String("response")
+
+	e, err := NewEntry(want, nil, time.Minute)
+	if err != nil {
+		t.Fatalf("cannot create entry: %s", err)
+	}
+
+	if err := s.Set("key", e); err != nil {
+		t.Fatalf("cannot write entry: %s", err)
+	}
+
+	stored, ok, err := s.Get("key")
+	if err != nil || !ok {
+		t.Fatalf("cannot read entry: ok = %t, err = %v", ok, err)
+	}
+
+	got, err := stored.Result()
+	if err != nil {
+		t.Fatalf("cannot decode entry: %s", err)
+	}
+
+	if !proto.Equal(got, want) {
+		t.Fatalf("got invalid result:\nwant:\t%v\ngot:\t%v", want, got)
+	}
+
+	e, _ = NewEntry(nil, grpc_status.Error(grpc_codes.AlreadyExists, "exists"), time.Minute)
+
+	if _, err := e.Result(); grpc_status.Code(err) != grpc_codes.AlreadyExists {
+		t.Fatalf("got invalid error: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+)
+
+// Fields is a set of key/value pairs attached to a log entry.
+type Fields map[string]interface{}
+
+// Logger is a minimal structured logger used by the package's entities.
+//
+// Use [NewLogrusLogger] or [NewSlogLogger] to obtain an implementation
+// backed by logrus or log/slog respectively.
+type Logger interface {
+	WithField(key string, value interface{}) Logger
+	WithFields(fields Fields) Logger
+	WithError(err error) Logger
+	WithContext(ctx context.Context) Logger
+
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type ctxLoggerKey struct{}
+
+// ToContext returns a new context carrying a given logger.
+//
+// If the logger is backed by logrus (see [NewLogrusLogger]), its entry
+// is also stored for ctxlogrus, so that handlers using ctxlogrus.Extract
+// keep working.
+func ToContext(ctx context.Context, logger Logger) context.Context {
+	if l, ok := logger.(*logrusLogger); ok {
+		ctx = ctxlogrus.ToContext(ctx, l.entry)
+	}
+
+	return context.WithValue(ctx, ctxLoggerKey{}, logger)
+}
+
+// Extract returns the logger stored in the context by [ToContext] with all
+// [grpc_ctxtags] tags added as fields. If there is no logger in the context,
+// a logger that discards all entries is returned.
+func Extract(ctx context.Context) Logger {
+	logger, ok := ctx.Value(ctxLoggerKey{}).(Logger)
+	if !ok {
+		return Discard
+	}
+
+	if tags := grpc_ctxtags.Extract(ctx).Values(); len(tags) > 0 {
+		return logger.WithFields(tags)
+	}
+
+	return logger
+}
+
+// NewDeferredLogger returns a logger that forwards each call to the logger
+// returned by fn at the time of the call.
+//
+// It allows to create entities (e.g. interceptors) during initialization
+// that will use the logger set later.
+func NewDeferredLogger(fn func() Logger) Logger {
+	return deferredLogger(fn)
+}
+
+type deferredLogger func() Logger
+
+func (fn deferredLogger) WithField(key string, value interface{}) Logger {
+	return fn().WithField(key, value)
+}
+
+func (fn deferredLogger) WithFields(fields Fields) Logger { return fn().WithFields(fields) }
+func (fn deferredLogger) WithError(err error) Logger      { return fn().WithError(err) }
+
+func (fn deferredLogger) WithContext(ctx context.Context) Logger { return fn().WithContext(ctx) }
+
+func (fn deferredLogger) Debug(args ...interface{}) { fn().Debug(args...) }
+func (fn deferredLogger) Info(args ...interface{})  { fn().Info(args...) }
+func (fn deferredLogger) Warn(args ...interface{})  { fn().Warn(args...) }
+func (fn deferredLogger) Error(args ...interface{}) { fn().Error(args...) }
+
+func (fn deferredLogger) Debugf(format string, args ...interface{}) { fn().Debugf(format, args...) }
+func (fn deferredLogger) Infof(format string, args ...interface{})  { fn().Infof(format, args...) }
+func (fn deferredLogger) Warnf(format string, args ...interface{})  { fn().Warnf(format, args...) }
+func (fn deferredLogger) Errorf(format string, args ...interface{}) { fn().Errorf(format, args...) }
+
+// Discard is a logger that discards all entries.
+var Discard Logger = discardLogger{}
+
+type discardLogger struct{}
+
+func (l discardLogger) WithField(string, interface{}) Logger { return l }
+func (l discardLogger) WithFields(Fields) Logger             { return l }
+func (l discardLogger) WithError(error) Logger               { return l }
+func (l discardLogger) WithContext(context.Context) Logger   { return l }
+func (discardLogger) Debug(...interface{})                   {}
+func (discardLogger) Info(...interface{})                    {}
+func (discardLogger) Warn(...interface{})                    {}
+func (discardLogger) Error(...interface{})                   {}
+func (discardLogger) Debugf(string, ...interface{})          {}
+func (discardLogger) Infof(string, ...interface{})           {}
+func (discardLogger) Warnf(string, ...interface{})           {}
+func (discardLogger) Errorf(string, ...interface{})          {}
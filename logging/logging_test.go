@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestExtractWithTags(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(buf, nil)))
+
+	ctx := grpc_ctxtags.SetInContext(context.Background(), grpc_ctxtags.NewTags().Set("request.uid", "ABCDEF"))
+
+	ctx = ToContext(ctx, logger.WithField("subsystem", "test"))
+
+	Extract(ctx).WithFields(Fields{"b": 2, "a": 1}).Infof("message %d", 1)
+
+	got := strings.TrimSpace(buf.String())
+
+	for _, want := range []string{`msg="message 1"`, "subsystem=test", "request.uid=ABCDEF", "a=1 b=2"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("got invalid log line:\nwant:\t%q\ngot:\t%q", want, got)
+		}
+	}
+}
+
+func TestExtractWithoutLogger(t *testing.T) {
+	if l := Extract(context.Background()); l != Discard {
+		t.Fatalf("got unexpected logger: %#v", l)
+	}
+}
+
+func TestToContextWithLogrus(t *testing.T) {
+	base, hook := logtest.NewNullLogger()
+
+	ctx := grpc_ctxtags.SetInContext(context.Background(), grpc_ctxtags.NewTags().Set("request.uid", "ABCDEF"))
+
+	ctx = ToContext(ctx, NewLogrusLogger(base.WithField("subsystem", "test")))
+
+	ctxlogrus.Extract(ctx).Info("message")
+
+	entry := hook.LastEntry()
+
+	if entry == nil || entry.Data["subsystem"] != "test" || entry.Data["request.uid"] != "ABCDEF" {
+		t.Fatalf("got unexpected log entry: %#v", entry)
+	}
+}
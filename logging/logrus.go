@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type logrusLogger struct {
+	entry *log.Entry
+}
+
+// NewLogrusLogger returns a [Logger] backed by a given logrus entry.
+func NewLogrusLogger(entry *log.Entry) Logger {
+	return &logrusLogger{entry: entry}
+}
+
+func (l *logrusLogger) WithField(key string, value interface{}) Logger {
+	return &logrusLogger{entry: l.entry.WithField(key, value)}
+}
+
+func (l *logrusLogger) WithFields(fields Fields) Logger {
+	return &logrusLogger{entry: l.entry.WithFields(log.Fields(fields))}
+}
+
+func (l *logrusLogger) WithError(err error) Logger {
+	return &logrusLogger{entry: l.entry.WithError(err)}
+}
+
+func (l *logrusLogger) WithContext(ctx context.Context) Logger {
+	return &logrusLogger{entry: l.entry.WithContext(ctx)}
+}
+
+func (l *logrusLogger) Debug(args ...interface{}) { l.entry.Debug(args...) }
+func (l *logrusLogger) Info(args ...interface{})  { l.entry.Info(args...) }
+func (l *logrusLogger) Warn(args ...interface{})  { l.entry.Warn(args...) }
+func (l *logrusLogger) Error(args ...interface{}) { l.entry.Error(args...) }
+
+func (l *logrusLogger) Debugf(format string, args ...interface{}) { l.entry.Debugf(format, args...) }
+func (l *logrusLogger) Infof(format string, args ...interface{})  { l.entry.Infof(format, args...) }
+func (l *logrusLogger) Warnf(format string, args ...interface{})  { l.entry.Warnf(format, args...) }
+func (l *logrusLogger) Errorf(format string, args ...interface{}) { l.entry.Errorf(format, args...) }
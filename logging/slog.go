@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+// NewSlogLogger returns a [Logger] backed by a given [slog.Logger].
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{
+		logger: logger,
+		ctx:    context.Background(),
+	}
+}
+
+func (l *slogLogger) WithField(key string, value interface{}) Logger {
+	return &slogLogger{logger: l.logger.With(key, value), ctx: l.ctx}
+}
+
+func (l *slogLogger) WithFields(fields Fields) Logger {
+	// Sort keys to get a stable order of attributes
+	keys := make([]string, 0, len(fields))
+
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	args := make([]interface{}, 0, 2*len(fields))
+
+	for _, k := range keys {
+		args = append(args, k, fields[k])
+	}
+
+	return &slogLogger{logger: l.logger.With(args...), ctx: l.ctx}
+}
+
+func (l *slogLogger) WithError(err error) Logger {
+	return &slogLogger{logger: l.logger.With("error", err), ctx: l.ctx}
+}
+
+func (l *slogLogger) WithContext(ctx context.Context) Logger {
+	return &slogLogger{logger: l.logger, ctx: ctx}
+}
+
+func (l *slogLogger) Debug(args ...interface{}) { l.logger.DebugContext(l.ctx, fmt.Sprint(args...)) }
+func (l *slogLogger) Info(args ...interface{})  { l.logger.InfoContext(l.ctx, fmt.Sprint(args...)) }
+func (l *slogLogger) Warn(args ...interface{})  { l.logger.WarnContext(l.ctx, fmt.Sprint(args...)) }
+func (l *slogLogger) Error(args ...interface{}) { l.logger.ErrorContext(l.ctx, fmt.Sprint(args...)) }
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.logger.DebugContext(l.ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.logger.InfoContext(l.ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.logger.WarnContext(l.ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.logger.ErrorContext(l.ctx, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,85 @@
+package method
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/0xef53/go-grpc/options"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// methodInfo holds the method properties obtained from the descriptor.
+type methodInfo struct {
+	policy     *options.MethodPolicy
+	idempotent bool
+}
+
+var cache sync.Map
+
+// Policy returns the call policy of a given method defined by the method option
+// of type [options.MethodPolicy]. The method name is expected in the gRPC form,
+// e.g. "/pkg.Service/Method".
+//
+// An empty policy is returned if the method is not found in the global registry
+// or has no such option.
+func Policy(fullMethod string) *options.MethodPolicy {
+	return lookup(fullMethod).policy
+}
+
+// Idempotent reports whether a given method is declared as idempotent
+// using the standard "idempotency_level" method option (IDEMPOTENT or
+// NO_SIDE_EFFECTS). The method name is expected in the gRPC form,
+// e.g. "/pkg.Service/Method".
+func Idempotent(fullMethod string) bool {
+	return lookup(fullMethod).idempotent
+}
+
+func lookup(fullMethod string) *methodInfo {
+	if v, ok := cache.Load(fullMethod); ok {
+		return v.(*methodInfo)
+	}
+
+	info := newMethodInfo(fullMethod)
+
+	cache.Store(fullMethod, info)
+
+	return info
+}
+
+func newMethodInfo(fullMethod string) *methodInfo {
+	info := &methodInfo{
+		policy: &options.MethodPolicy{},
+	}
+
+	name := strings.Replace(strings.TrimPrefix(fullMethod, "/"), "/", ".", 1)
+
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return info
+	}
+
+	md, ok := d.(protoreflect.MethodDescriptor)
+	if !ok {
+		return info
+	}
+
+	opts, ok := md.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		return info
+	}
+
+	if p, ok := proto.GetExtension(opts, options.E_CallPolicy).(*options.MethodPolicy); ok && p != nil {
+		info.policy = p
+	}
+
+	switch opts.GetIdempotencyLevel() {
+	case descriptorpb.MethodOptions_IDEMPOTENT, descriptorpb.MethodOptions_NO_SIDE_EFFECTS:
+		info.idempotent = true
+	}
+
+	return info
+}
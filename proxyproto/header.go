@@ -0,0 +1,161 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	// ErrInvalidHeader is returned if the PROXY protocol header is malformed.
+	ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+)
+
+const (
+	// The maximum length of the v1 header including CRLF
+	v1MaxLength = 107
+
+	v2CmdLocal = 0x0
+	v2CmdProxy = 0x1
+
+	v2FamTCP4 = 0x11
+	v2FamTCP6 = 0x21
+)
+
+// readHeader reads the PROXY protocol header of version 1 or 2 from a given reader.
+//
+// The returned addresses are nil if there is no header, or the header does not
+// contain the addresses (v1 "UNKNOWN", v2 "LOCAL" command or unsupported address family).
+func readHeader(r *bufio.Reader) (src, dst net.Addr, err error) {
+	if b, err := r.Peek(len(v2Signature)); err == nil && bytes.Equal(b, v2Signature) {
+		return readHeaderV2(r)
+	}
+
+	if b, err := r.Peek(len(v1Prefix)); err == nil && bytes.Equal(b, v1Prefix) {
+		return readHeaderV1(r)
+	}
+
+	return nil, nil, nil
+}
+
+func readHeaderV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+
+	for len(line) < v1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line = append(line, b)
+
+		if b == '\n' {
+			break
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, fmt.Errorf("%w: v1 header is too long or not terminated", ErrInvalidHeader)
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidHeader, line)
+	}
+
+	src, err := parseV1Addr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dst, err := parseV1Addr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return src, dst, nil
+}
+
+func parseV1Addr(proto, ip, port string) (net.Addr, error) {
+	addr := net.ParseIP(ip)
+
+	if addr == nil || (proto == "TCP4") != (addr.To4() != nil) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidHeader, ip)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid port %q", ErrInvalidHeader, port)
+	}
+
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+func readHeaderV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	hdr := make([]byte, 16)
+
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, nil, err
+	}
+
+	if hdr[12]>>4 != 0x2 {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, hdr[12]>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+
+	switch hdr[12] & 0x0F {
+	case v2CmdLocal:
+		// Health checks and other connections established by the proxy itself
+		return nil, nil, nil
+	case v2CmdProxy:
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported command %d", ErrInvalidHeader, hdr[12]&0x0F)
+	}
+
+	var ipLen int
+
+	switch hdr[13] {
+	case v2FamTCP4:
+		ipLen = net.IPv4len
+	case v2FamTCP6:
+		ipLen = net.IPv6len
+	default:
+		// Unsupported family: the addresses are ignored
+		return nil, nil, nil
+	}
+
+	if len(payload) < 2*ipLen+4 {
+		return nil, nil, fmt.Errorf("%w: address block is too short", ErrInvalidHeader)
+	}
+
+	src := &net.TCPAddr{
+		IP:   net.IP(append([]byte{}, payload[:ipLen]...)),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLen:])),
+	}
+
+	dst := &net.TCPAddr{
+		IP:   net.IP(append([]byte{}, payload[ipLen:2*ipLen]...)),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLen+2:])),
+	}
+
+	return src, dst, nil
+}
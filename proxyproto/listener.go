@@ -0,0 +1,193 @@
+// Package proxyproto implements a listener that parses the PROXY protocol
+// (version 1 and 2) header sent by load balancers like HAProxy.
+//
+// The header is parsed only for connections from trusted sources. The addresses
+// from the header are returned by the RemoteAddr and LocalAddr methods of
+// the accepted connections, so they are seen by the gRPC peer and
+// the http.Request.RemoteAddr.
+package proxyproto
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"time"
+)
+
+// HeaderTimeout is the maximum time to wait for the PROXY protocol header.
+const HeaderTimeout = 10 * time.Second
+
+// Conn is a connection accepted by the listener.
+type Conn struct {
+	net.Conn
+
+	r      *bufio.Reader
+	remote net.Addr
+	local  net.Addr
+}
+
+// Read reads data from the connection.
+func (c *Conn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the client address from the PROXY protocol header
+// or the remote address of the underlying connection if there was no header.
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the destination address from the PROXY protocol header
+// or the local address of the underlying connection if there was no header.
+func (c *Conn) LocalAddr() net.Addr {
+	if c.local != nil {
+		return c.local
+	}
+
+	return c.Conn.LocalAddr()
+}
+
+// ProxyAddr returns the remote address of the underlying connection,
+// i.e. the address of the load balancer.
+func (c *Conn) ProxyAddr() net.Addr {
+	return c.Conn.RemoteAddr()
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+type listener struct {
+	net.Listener
+
+	trusted func(net.Addr) bool
+	onError func(net.Conn, error)
+
+	once   sync.Once
+	conns  chan acceptResult
+	done   chan struct{}
+	closed sync.Once
+}
+
+// NewListener returns a listener that parses the PROXY protocol header of connections
+// from the sources for which the trusted function reports true. The header is optional:
+// connections without it are passed as is. Connections from untrusted sources
+// are never parsed.
+//
+// The header of each connection is read in a separate goroutine, so slow clients
+// do not block the accepting of other connections. Connections with a malformed header
+// are closed, and the onError function, if not nil, is called before that.
+func NewListener(l net.Listener, trusted func(net.Addr) bool, onError func(net.Conn, error)) net.Listener {
+	return &listener{
+		Listener: l,
+		trusted:  trusted,
+		onError:  onError,
+		conns:    make(chan acceptResult),
+		done:     make(chan struct{}),
+	}
+}
+
+// Accept waits for and returns the next connection with the parsed header.
+func (l *listener) Accept() (net.Conn, error) {
+	l.once.Do(func() { go l.acceptLoop() })
+
+	select {
+	case res := <-l.conns:
+		return res.conn, res.err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener.
+func (l *listener) Close() error {
+	l.closed.Do(func() { close(l.done) })
+
+	return l.Listener.Close()
+}
+
+func (l *listener) acceptLoop() {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			temporary := false
+
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				temporary = true
+			}
+
+			// A permanent error is returned by all subsequent Accept calls
+			for {
+				select {
+				case l.conns <- acceptResult{err: err}:
+				case <-l.done:
+					return
+				}
+
+				if temporary {
+					break
+				}
+			}
+
+			continue
+		}
+
+		if !l.trusted(c.RemoteAddr()) {
+			l.deliver(c)
+
+			continue
+		}
+
+		go func() {
+			conn, err := l.readHeader(c)
+			if err != nil {
+				if l.onError != nil {
+					l.onError(c, err)
+				}
+
+				c.Close()
+
+				return
+			}
+
+			l.deliver(conn)
+		}()
+	}
+}
+
+func (l *listener) deliver(c net.Conn) {
+	select {
+	case l.conns <- acceptResult{conn: c}:
+	case <-l.done:
+		c.Close()
+	}
+}
+
+func (l *listener) readHeader(c net.Conn) (net.Conn, error) {
+	if err := c.SetReadDeadline(time.Now().Add(HeaderTimeout)); err != nil {
+		return nil, err
+	}
+
+	conn := &Conn{
+		Conn: c,
+		r:    bufio.NewReader(c),
+	}
+
+	src, dst, err := readHeader(conn.r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	conn.remote, conn.local = src, dst
+
+	return conn, nil
+}
@@ -0,0 +1,125 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func v2Header(cmd byte, src, dst net.IP, sport, dport uint16) []byte {
+	var buf bytes.Buffer
+
+	buf.Write(v2Signature)
+	buf.WriteByte(0x20 | cmd)
+
+	addrs := append(append([]byte{}, src...), dst...)
+	addrs = binary.BigEndian.AppendUint16(addrs, sport)
+	addrs = binary.BigEndian.AppendUint16(addrs, dport)
+
+	if len(src) == net.IPv4len {
+		buf.WriteByte(v2FamTCP4)
+	} else {
+		buf.WriteByte(v2FamTCP6)
+	}
+
+	buf.Write(binary.BigEndian.AppendUint16(nil, uint16(len(addrs))))
+	buf.Write(addrs)
+
+	return buf.Bytes()
+}
+
+func TestReadHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		wantSrc string
+		wantErr bool
+	}{
+		{"v1 tcp4", []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nDATA"), "192.0.2.1:56324", false},
+		{"v1 tcp6", []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\nDATA"), "[2001:db8::1]:56324", false},
+		{"v1 unknown", []byte("PROXY UNKNOWN\r\nDATA"), "", false},
+		{"v1 invalid", []byte("PROXY TCP4 2001:db8::1 192.0.2.1 1 2\r\nDATA"), "", true},
+		{"v1 unterminated", []byte("PROXY TCP4 " + strings.Repeat("1", 200)), "", true},
+		{"v2 tcp4", append(v2Header(v2CmdProxy, net.IPv4(192, 0, 2, 1).To4(), net.IPv4(198, 51, 100, 1).To4(), 56324, 443), "DATA"...), "192.0.2.1:56324", false},
+		{"v2 tcp6", append(v2Header(v2CmdProxy, net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2"), 56324, 443), "DATA"...), "[2001:db8::1]:56324", false},
+		{"v2 local", append(v2Header(v2CmdLocal, net.IPv4(192, 0, 2, 1).To4(), net.IPv4(198, 51, 100, 1).To4(), 1, 2), "DATA"...), "", false},
+		{"no header", []byte("DATA"), "", false},
+	}
+
+	for _, tt := range tests {
+		r := bufio.NewReader(bytes.NewReader(tt.input))
+
+		src, _, err := readHeader(r)
+
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		if tt.wantErr {
+			continue
+		}
+
+		if got := addrString(src); got != tt.wantSrc {
+			t.Errorf("%s: source address: got %q, want %q", tt.name, got, tt.wantSrc)
+		}
+
+		if rest, _ := io.ReadAll(r); string(rest) != "DATA" {
+			t.Errorf("%s: unexpected payload after header: %q", tt.name, rest)
+		}
+	}
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+
+	return addr.String()
+}
+
+func TestListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pl := NewListener(l, func(net.Addr) bool { return true }, nil)
+	defer pl.Close()
+
+	// A slow client must not block the others
+	slow, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+
+	slow.Write([]byte("PROXY TCP4"))
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello"))
+
+	conn, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if got := conn.RemoteAddr().String(); got != "192.0.2.1:56324" {
+		t.Errorf("unexpected remote address: %s", got)
+	}
+
+	buf := make([]byte, 5)
+
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "hello" {
+		t.Errorf("unexpected payload: %q (%v)", buf, err)
+	}
+}
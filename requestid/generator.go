@@ -0,0 +1,127 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Generator is a function that returns a new unique request ID.
+type Generator func() string
+
+// crockford is the Crockford's Base32 alphabet used by ULID.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Random returns a generator of crypto-random IDs of a given length
+// consisting of digits and uppercase letters (Crockford's Base32 alphabet).
+func Random(length int) Generator {
+	return func() string {
+		b := make([]byte, length)
+
+		rand.Read(b)
+
+		for i := range b {
+			// 256 is a multiple of 32, so there is no modulo bias
+			b[i] = crockford[b[i]%32]
+		}
+
+		return string(b)
+	}
+}
+
+// UUIDv7 returns a generator of time-ordered UUIDs version 7 (RFC 9562).
+func UUIDv7() Generator {
+	return func() string {
+		var u [16]byte
+
+		rand.Read(u[6:])
+
+		ms := uint64(time.Now().UnixMilli())
+
+		u[0] = byte(ms >> 40)
+		u[1] = byte(ms >> 32)
+		binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+
+		u[6] = (u[6] & 0x0F) | 0x70 // version 7
+		u[8] = (u[8] & 0x3F) | 0x80 // variant 10
+
+		var buf [36]byte
+
+		hex.Encode(buf[0:8], u[0:4])
+		buf[8] = '-'
+		hex.Encode(buf[9:13], u[4:6])
+		buf[13] = '-'
+		hex.Encode(buf[14:18], u[6:8])
+		buf[18] = '-'
+		hex.Encode(buf[19:23], u[8:10])
+		buf[23] = '-'
+		hex.Encode(buf[24:], u[10:])
+
+		return string(buf[:])
+	}
+}
+
+// ULID returns a generator of lexicographically sortable identifiers
+// (https://github.com/ulid/spec).
+func ULID() Generator {
+	return func() string {
+		var u [16]byte
+
+		rand.Read(u[6:])
+
+		ms := uint64(time.Now().UnixMilli())
+
+		u[0] = byte(ms >> 40)
+		u[1] = byte(ms >> 32)
+		binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+
+		// 128 bits are encoded into 26 characters of 5 bits,
+		// the first character holds only 3 bits
+		var buf [26]byte
+
+		hi := binary.BigEndian.Uint64(u[:8])
+		lo := binary.BigEndian.Uint64(u[8:])
+
+		for i := 25; i >= 0; i-- {
+			buf[i] = crockford[lo&0x1F]
+
+			lo = lo>>5 | hi<<59
+			hi >>= 5
+		}
+
+		return string(buf[:])
+	}
+}
+
+// ParseGenerator returns a generator by its name. Supported names are
+// "random" (the length can be specified after a colon, e.g. "random:16"),
+// "uuidv7" and "ulid". It is intended to be used with configuration files.
+func ParseGenerator(s string) (Generator, error) {
+	name, arg, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+
+	switch name {
+	case "random":
+		length := DefaultLength
+
+		if len(arg) > 0 {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid request ID length: %q", arg)
+			}
+
+			length = n
+		}
+
+		return Random(length), nil
+	case "uuidv7", "uuid":
+		return UUIDv7(), nil
+	case "ulid":
+		return ULID(), nil
+	}
+
+	return nil, fmt.Errorf("unknown request ID generator: %q", s)
+}
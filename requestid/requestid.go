@@ -0,0 +1,171 @@
+// Package requestid implements the generation, chaining and parsing of request IDs.
+//
+// A request ID of a call made while serving another call is a chain of IDs separated
+// by a colon: "<root>:<parent>:<current>". The first element is the ID of the call
+// that started the whole chain, the last one is the ID of the current call.
+//
+// The package settings are global. They should be configured during initialization,
+// and it is strongly recommended not to change them afterward.
+package requestid
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMetadataKey is the default gRPC metadata key carrying the request ID.
+	DefaultMetadataKey = "request-id"
+
+	// DefaultHeader is the default HTTP header carrying the request ID.
+	DefaultHeader = "X-Request-Id"
+
+	// DefaultLength is the default length of the IDs made by the [Random] generator.
+	DefaultLength = 12
+
+	// DefaultMaxDepth is the default maximum number of elements in a chain.
+	DefaultMaxDepth = 8
+
+	// Separator separates the elements of a chain.
+	Separator = ":"
+)
+
+var settings = struct {
+	sync.RWMutex
+
+	generator   Generator
+	metadataKey string
+	header      string
+	maxDepth    int
+}{
+	generator:   Random(DefaultLength),
+	metadataKey: DefaultMetadataKey,
+	header:      DefaultHeader,
+	maxDepth:    DefaultMaxDepth,
+}
+
+// SetGenerator sets the generator of new request IDs.
+// By default, [Random] with [DefaultLength] is used.
+func SetGenerator(g Generator) {
+	settings.Lock()
+	defer settings.Unlock()
+
+	settings.generator = g
+}
+
+// SetMetadataKey sets the gRPC metadata key carrying the request ID.
+// The key is converted to lowercase.
+func SetMetadataKey(key string) {
+	settings.Lock()
+	defer settings.Unlock()
+
+	settings.metadataKey = strings.ToLower(key)
+}
+
+// SetHeader sets the HTTP header carrying the request ID.
+func SetHeader(header string) {
+	settings.Lock()
+	defer settings.Unlock()
+
+	settings.header = header
+}
+
+// SetMaxDepth sets the maximum number of elements in a chain.
+// Longer chains are compacted (see [Chain]). Values less than 2 are ignored.
+func SetMaxDepth(n int) {
+	if n < 2 {
+		return
+	}
+
+	settings.Lock()
+	defer settings.Unlock()
+
+	settings.maxDepth = n
+}
+
+// MetadataKey returns the gRPC metadata key carrying the request ID.
+func MetadataKey() string {
+	settings.RLock()
+	defer settings.RUnlock()
+
+	return settings.metadataKey
+}
+
+// Header returns the HTTP header carrying the request ID.
+func Header() string {
+	settings.RLock()
+	defer settings.RUnlock()
+
+	return settings.header
+}
+
+// New returns a new request ID made by the configured generator.
+func New() string {
+	settings.RLock()
+	g := settings.generator
+	settings.RUnlock()
+
+	return g()
+}
+
+// Chain appends a given ID to the parent chain.
+//
+// If the resulting chain is longer than the maximum depth, it is compacted:
+// the root element and the latest elements are kept.
+func Chain(parent, id string) string {
+	if len(parent) == 0 {
+		return id
+	}
+
+	return Compact(parent + Separator + id)
+}
+
+// Compact shortens a given chain to the maximum depth by removing
+// the elements following the root.
+func Compact(chain string) string {
+	settings.RLock()
+	maxDepth := settings.maxDepth
+	settings.RUnlock()
+
+	parts := Split(chain)
+
+	if len(parts) <= maxDepth {
+		return chain
+	}
+
+	parts = append(parts[:1], parts[len(parts)-maxDepth+1:]...)
+
+	return strings.Join(parts, Separator)
+}
+
+// Split returns the elements of a given chain.
+func Split(chain string) []string {
+	return strings.Split(chain, Separator)
+}
+
+// Root returns the first element of a given chain, i.e. the ID
+// of the call that started the chain.
+func Root(chain string) string {
+	root, _, _ := strings.Cut(chain, Separator)
+
+	return root
+}
+
+// Parent returns the element preceding the last one in a given chain,
+// or an empty string if the chain has only one element.
+func Parent(chain string) string {
+	parts := Split(chain)
+
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[len(parts)-2]
+}
+
+// Current returns the last element of a given chain.
+func Current(chain string) string {
+	parts := Split(chain)
+
+	return parts[len(parts)-1]
+}
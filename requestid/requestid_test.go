@@ -0,0 +1,88 @@
+package requestid
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		name string
+		gen  Generator
+		re   *regexp.Regexp
+	}{
+		{"random", Random(16), regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{16}$`)},
+		{"uuidv7", UUIDv7(), regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"ulid", ULID(), regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)},
+	}
+
+	for _, tt := range tests {
+		seen := make(map[string]struct{})
+
+		for i := 0; i < 1000; i++ {
+			id := tt.gen()
+
+			if !tt.re.MatchString(id) {
+				t.Fatalf("%s: invalid ID format: %q", tt.name, id)
+			}
+
+			if _, ok := seen[id]; ok {
+				t.Fatalf("%s: duplicate ID: %q", tt.name, id)
+			}
+
+			seen[id] = struct{}{}
+		}
+	}
+}
+
+func TestParseGenerator(t *testing.T) {
+	g, err := ParseGenerator("random:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := g(); len(id) != 5 {
+		t.Errorf("unexpected ID length: %q", id)
+	}
+
+	for _, s := range []string{"random:0", "random:x", "sequence"} {
+		if _, err := ParseGenerator(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	SetMaxDepth(4)
+	defer SetMaxDepth(DefaultMaxDepth)
+
+	chain := "A"
+
+	for _, id := range []string{"B", "C", "D", "E", "F"} {
+		chain = Chain(chain, id)
+	}
+
+	if chain != "A:D:E:F" {
+		t.Fatalf("unexpected chain: %q", chain)
+	}
+
+	if got := Root(chain); got != "A" {
+		t.Errorf("unexpected root: %q", got)
+	}
+
+	if got := Parent(chain); got != "E" {
+		t.Errorf("unexpected parent: %q", got)
+	}
+
+	if got := Current(chain); got != "F" {
+		t.Errorf("unexpected current: %q", got)
+	}
+
+	if got := Parent("A"); got != "" {
+		t.Errorf("unexpected parent of a single ID: %q", got)
+	}
+
+	if got := Chain("", "A"); got != "A" {
+		t.Errorf("unexpected chain without parent: %q", got)
+	}
+}
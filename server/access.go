@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net"
+	"path"
+
+	"github.com/0xef53/go-grpc/server/interceptors"
+)
+
+// accessDecider returns a function that checks the client address against
+// the global access list and the access rules matching the method.
+// The address must be allowed by the global list and by each matching rule.
+// It returns nil if there is nothing to check.
+func (s *Server) accessDecider() interceptors.AccessDecider {
+	// Already validated
+	global, _ := s.config.accessList()
+	rules, _ := s.config.accessRules()
+
+	if global.Empty() && len(rules) == 0 {
+		return nil
+	}
+
+	return func(fullMethod string, addr net.Addr) bool {
+		if !global.AllowedAddr(addr) {
+			return false
+		}
+
+		if len(rules) > 0 {
+			buckets := s.drain.bucketsOf(path.Dir(fullMethod)[1:])
+
+			for _, r := range rules {
+				if r.Match(fullMethod, buckets) && !r.List.AllowedAddr(addr) {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net"
+	"testing"
+)
+
+func TestAccessDecider(t *testing.T) {
+	s := newTestServer(t)
+
+	s.config.AllowFrom = []string{"10.0.0.0/8"}
+	s.config.AccessRules = []string{
+		"/pkg.Svc/Delete allow 10.1.0.0/16",
+		"/pkg.Svc/ deny 10.1.2.0/24",
+	}
+
+	decider := s.accessDecider()
+
+	tests := []struct {
+		method string
+		addr   string
+		want   bool
+	}{
+		{"/pkg.Svc/Get", "10.2.0.1", true},
+		{"/pkg.Svc/Get", "192.168.0.1", false},
+		// The method rule does not extend the global list
+		{"/pkg.Svc/Delete", "10.1.0.1", true},
+		{"/pkg.Svc/Delete", "10.2.0.1", false},
+		{"/pkg.Svc/Delete", "192.168.0.1", false},
+		// Denied by the service rule
+		{"/pkg.Svc/Delete", "10.1.2.1", false},
+		{"/pkg.Svc/Get", "10.1.2.1", false},
+		{"/pkg.Other/Get", "10.1.2.1", true},
+	}
+
+	for _, tt := range tests {
+		addr := &net.TCPAddr{IP: net.ParseIP(tt.addr), Port: 10000}
+
+		if got := decider(tt.method, addr); got != tt.want {
+			t.Errorf("%s from %s: got %t, want %t", tt.method, tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strings"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/reflection"
+	grpc_reflection_v1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	grpc_reflection_v1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// adminServer represents an opt-in admin/debug server. It serves both gRPC
+// (channelz and reflection services) and HTTP (pprof, registry dump,
+// drain mode switch and client connections) requests on the same listener.
+type adminServer struct {
+	server *Server
+
+	grpcServer *grpc.Server
+	mux        *http.ServeMux
+}
+
+func newAdminServer(s *Server) *adminServer {
+	a := &adminServer{
+		server:     s,
+		grpcServer: grpc.NewServer(),
+		mux:        http.NewServeMux(),
+	}
+
+	channelz.RegisterChannelzServiceToServer(a.grpcServer)
+
+	// The reflection service describes both the main server services
+	// and the admin server services.
+	reflectionOpts := reflection.ServerOptions{
+		Services: serviceInfoProviders{s.grpcServer, a.grpcServer},
+	}
+
+	grpc_reflection_v1.RegisterServerReflectionServer(a.grpcServer, reflection.NewServerV1(reflectionOpts))
+	grpc_reflection_v1alpha.RegisterServerReflectionServer(a.grpcServer, reflection.NewServer(reflectionOpts))
+
+	a.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	a.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	a.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	a.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	a.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	a.mux.HandleFunc("/debug/registry", a.registryHandler)
+	a.mux.HandleFunc("/debug/drain", s.drainHandler)
+	a.mux.HandleFunc("/debug/connections", s.connectionsHandler)
+
+	return a
+}
+
+// ServeHTTP dispatches gRPC requests to the admin gRPC server
+// and all other requests to the HTTP mux.
+func (a *adminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		a.grpcServer.ServeHTTP(w, r)
+
+		return
+	}
+
+	a.mux.ServeHTTP(w, r)
+}
+
+// serve serves the admin server on the given listener until the context is done.
+func (a *adminServer) serve(ctx context.Context, l net.Listener) error {
+	protocols := new(http.Protocols)
+
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	httpServer := &http.Server{
+		Handler:   a,
+		Protocols: protocols,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		httpServer.Shutdown(context.Background())
+
+		a.grpcServer.Stop()
+	}()
+
+	logger.WithFields(logging.Fields{"addr": l.Addr().String()}).Info("Starting admin server")
+
+	if err := httpServer.Serve(l); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	logger.WithFields(logging.Fields{"addr": l.Addr().String()}).Info("Admin server stopped")
+
+	return nil
+}
+
+type registryService struct {
+	Methods  []string    `json:"methods"`
+	Metadata interface{} `json:"metadata,omitempty"`
+}
+
+type registryDump struct {
+	Buckets        map[string][]string        `json:"buckets"`
+	ServingBuckets []string                   `json:"serving_buckets"`
+	Services       map[string]registryService `json:"services"`
+	Config         *Config                    `json:"config"`
+}
+
+// registryHandler writes a JSON dump of registered buckets, services and methods
+// together with the effective server configuration.
+func (a *adminServer) registryHandler(w http.ResponseWriter, r *http.Request) {
+	dump := registryDump{
+		Buckets:        make(map[string][]string),
+		ServingBuckets: a.server.buckets,
+		Services:       make(map[string]registryService),
+		Config:         a.server.config,
+	}
+
+	for _, bucket := range Buckets() {
+		names := make([]string, 0)
+
+		for _, svc := range Services(bucket) {
+			names = append(names, svc.Name())
+		}
+
+		dump.Buckets[bucket] = names
+	}
+
+	for name, info := range a.server.grpcServer.GetServiceInfo() {
+		methods := make([]string, 0, len(info.Methods))
+
+		for _, m := range info.Methods {
+			methods = append(methods, m.Name)
+		}
+
+		sort.Strings(methods)
+
+		dump.Services[name] = registryService{
+			Methods:  methods,
+			Metadata: info.Metadata,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+
+	enc.SetIndent("", "    ")
+
+	if err := enc.Encode(&dump); err != nil {
+		logger.WithError(err).Error("Cannot encode registry dump")
+	}
+}
+
+// serviceInfoProviders merges service info of several gRPC servers.
+type serviceInfoProviders []reflection.ServiceInfoProvider
+
+func (p serviceInfoProviders) GetServiceInfo() map[string]grpc.ServiceInfo {
+	m := make(map[string]grpc.ServiceInfo)
+
+	for _, provider := range p {
+		for k, v := range provider.GetServiceInfo() {
+			m[k] = v
+		}
+	}
+
+	return m
+}
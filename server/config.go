@@ -6,8 +6,15 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/0xef53/go-grpc/acl"
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/proxyproto"
 	"github.com/0xef53/go-grpc/utils"
+
+	"google.golang.org/grpc/encoding"
 )
 
 // Config represents a gRPC server / gRPC Gateway server config
@@ -31,6 +38,79 @@ type Config struct {
 	GRPCSocketPath   string `gcfg:"-" ini:"-" json:"-"`
 	GRPCSecureSocket bool   `gcfg:"-" ini:"-" json:"-"`
 
+	// AdminBinding specifies the address of the optional admin/debug server
+	// (channelz, reflection, pprof and registry dump).
+	// It can be a TCP "host:port" pair or a Unix socket path prefixed
+	// with "unix:". If empty, the admin server is disabled.
+	//
+	// The admin server has no authentication, so it must be bound to a loopback
+	// address ("localhost" or an IP address) or a Unix socket. AllowFrom and DenyFrom are applied to its
+	// TCP connections, but AccessRules are not.
+	AdminBinding string `gcfg:"admin-listen" ini:"admin-listen" json:"admin_listen"`
+
+	// LogResponses enables logging of response tags, status code and duration
+	// on the server side.
+	LogResponses bool `gcfg:"log-responses" ini:"log-responses" json:"log_responses"`
+
+	// LogResponsesMethods restricts response logging to the given list of
+	// full method names ("/package.Service/Method") or service names
+	// ("/package.Service/"). If empty, responses of all methods are logged.
+	LogResponsesMethods []string `gcfg:"log-responses-method" ini:"log-responses-method,,allowshadow" json:"log_responses_methods"`
+
+	// LogStreamMessages enables logging of the tags of each message
+	// sent or received over the server streams.
+	LogStreamMessages bool `gcfg:"log-stream-messages" ini:"log-stream-messages" json:"log_stream_messages"`
+
+	// Compression specifies the name of the compressor used for responses
+	// by default, e.g. "gzip" or "zstd". It is applied only if the client
+	// supports it. The method option of type [options.MethodPolicy] takes
+	// precedence over this value. If empty, responses are compressed in the same
+	// way as the requests.
+	Compression string `gcfg:"compression" ini:"compression" json:"compression"`
+
+	// GatewayCompression enables gzip encoding of the gRPC Gateway responses
+	// for clients that accept it.
+	GatewayCompression bool `gcfg:"compression-gw" ini:"compression-gw" json:"compression_gw"`
+
+	// GatewayMaxRequestSize is the maximum size in bytes of a decompressed
+	// gzip-encoded request body. Larger requests are answered with
+	// "413 Request Entity Too Large". If zero, the limit is 4 MiB.
+	GatewayMaxRequestSize int64 `gcfg:"max-request-size-gw" ini:"max-request-size-gw" json:"max_request_size_gw"`
+
+	// GatewayBackend specifies the gRPC target the gRPC Gateway forwards
+	// the requests to, e.g. "file:///etc/app/backends.json" to balance them
+	// across the endpoints listed in the file (see the client/resolver package).
+	// If empty, the requests are forwarded to the local server via GRPCSocketPath.
+	GatewayBackend string `gcfg:"backend-gw" ini:"backend-gw" json:"backend_gw"`
+
+	// AllowFrom and DenyFrom specify the networks (in CIDR notation) or single
+	// IP addresses the clients are allowed or denied to connect from.
+	// Denied networks take precedence. If AllowFrom is empty, all clients that
+	// are not denied are allowed. The lists are enforced when accepting connections
+	// on the listeners and for each call.
+	AllowFrom []string `gcfg:"allow-from" ini:"allow-from,,allowshadow" json:"allow_from"`
+	DenyFrom  []string `gcfg:"deny-from" ini:"deny-from,,allowshadow" json:"deny_from"`
+
+	// AccessRules specifies additional access lists for buckets, services
+	// or methods in the form "<target> allow|deny <network>[,<network>...]",
+	// where the target is "bucket:<name>", "/package.Service/" or
+	// "/package.Service/Method" (see [acl.ParseRule]).
+	// The rules are enforced for each call together with AllowFrom and DenyFrom:
+	// the client must be allowed by the global list and by each matching rule.
+	AccessRules []string `gcfg:"access-rule" ini:"access-rule,,allowshadow" json:"access_rules"`
+
+	// ProxyProtocol specifies the bindings (IP addresses or interface names,
+	// see the "Bindings" field) on which the listeners accept the PROXY protocol
+	// header (version 1 or 2) from load balancers. The client address from
+	// the header is used as the peer address of the calls and as the remote
+	// address of the gateway requests.
+	ProxyProtocol []string `gcfg:"proxy-protocol" ini:"proxy-protocol,,allowshadow" json:"proxy_protocol"`
+
+	// ProxyProtocolTrusted specifies the networks (in CIDR notation) or single
+	// IP addresses of the load balancers allowed to send the PROXY protocol header.
+	// Connections from other sources are used as is.
+	ProxyProtocolTrusted []string `gcfg:"proxy-protocol-trusted" ini:"proxy-protocol-trusted,,allowshadow" json:"proxy_protocol_trusted"`
+
 	// TLSConfig is used to configure TLS encryption for the connection.
 	TLSConfig *tls.Config `gcfg:"-" ini:"-" json:"-"`
 }
@@ -50,7 +130,7 @@ func (c *Config) Defaults() {
 	}
 
 	if len(c.GRPCSocketPath) == 0 {
-		c.GRPCSocketPath = filepath.Join("/run", fmt.Sprintf("%s_%d.sock", filepath.Base(os.Args[0]), os.Getpid()))
+		c.GRPCSocketPath = utils.SocketPath(filepath.Base(os.Args[0]), os.Getpid())
 	}
 }
 
@@ -76,6 +156,44 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("gRPC unix socket path is not set")
 	}
 
+	if len(c.Compression) > 0 && c.Compression != encoding.Identity {
+		if encoding.GetCompressor(c.Compression) == nil {
+			return fmt.Errorf("unknown compressor: %s", c.Compression)
+		}
+	}
+
+	if len(c.ProxyProtocol) > 0 {
+		if len(c.ProxyProtocolTrusted) == 0 {
+			return fmt.Errorf("PROXY protocol is enabled, but no trusted sources are defined")
+		}
+
+		if _, err := acl.NewList(c.ProxyProtocolTrusted, nil); err != nil {
+			return fmt.Errorf("invalid PROXY protocol trusted sources: %w", err)
+		}
+	}
+
+	if _, err := c.accessList(); err != nil {
+		return err
+	}
+
+	if _, err := c.accessRules(); err != nil {
+		return err
+	}
+
+	if len(c.AdminBinding) > 0 {
+		if network, addr := c.adminAddr(); network == "tcp" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return fmt.Errorf("invalid admin server address: %w", err)
+			}
+
+			// The admin server has no authentication
+			if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+				return fmt.Errorf("admin server must be bound to a loopback address or a unix socket: %s", addr)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -108,6 +226,35 @@ func (c *Config) listeners(addrs []net.IP, port uint16) (_ []net.Listener, err e
 		listeners = append(listeners, l)
 	}
 
+	proxied, err := utils.ParseBindings(c.ProxyProtocol...)
+	if err != nil {
+		return nil, err
+	}
+
+	trusted, err := acl.NewList(c.ProxyProtocolTrusted, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.accessList()
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range listeners {
+		// The PROXY protocol header should be parsed before the access list
+		// is applied to get the real client address
+		if slices.ContainsFunc(proxied, addrs[idx].Equal) && !trusted.Empty() {
+			listeners[idx] = proxyproto.NewListener(listeners[idx], trusted.AllowedAddr, func(conn net.Conn, err error) {
+				logger.WithFields(logging.Fields{"addr": conn.LocalAddr().String(), "peer.address": conn.RemoteAddr().String()}).WithError(err).Warn("Cannot read PROXY protocol header")
+			})
+		}
+
+		listeners[idx] = acl.NewListener(listeners[idx], list, func(conn net.Conn) {
+			logger.WithFields(logging.Fields{"addr": conn.LocalAddr().String(), "peer.address": conn.RemoteAddr().String()}).Warn("Connection rejected by access list")
+		})
+	}
+
 	return listeners, nil
 }
 
@@ -132,3 +279,85 @@ func (c *Config) GetGatewayListeners() ([]net.Listener, error) {
 
 	return c.listeners(addrs, c.GatewayPort)
 }
+
+// logResponsesDecider returns a function that decides whether the response
+// of a given method should be logged according to the "LogResponses*" fields.
+func (c *Config) logResponsesDecider() func(string) bool {
+	if !c.LogResponses {
+		return func(string) bool { return false }
+	}
+
+	if len(c.LogResponsesMethods) == 0 {
+		return func(string) bool { return true }
+	}
+
+	return func(fullMethod string) bool {
+		for _, m := range c.LogResponsesMethods {
+			if m == fullMethod || (strings.HasSuffix(m, "/") && strings.HasPrefix(fullMethod, m)) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// accessList returns the global access list obtained from the "AllowFrom"
+// and "DenyFrom" fields.
+func (c *Config) accessList() (*acl.List, error) {
+	list, err := acl.NewList(c.AllowFrom, c.DenyFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access list: %w", err)
+	}
+
+	return list, nil
+}
+
+// accessRules returns the access rules obtained from the "AccessRules" field.
+func (c *Config) accessRules() ([]*acl.Rule, error) {
+	rules := make([]*acl.Rule, 0, len(c.AccessRules))
+
+	for _, s := range c.AccessRules {
+		r, err := acl.ParseRule(s)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// adminAddr returns the network type and the address of the admin server
+// obtained from the "AdminBinding" field.
+func (c *Config) adminAddr() (string, string) {
+	if strings.HasPrefix(c.AdminBinding, "unix:") {
+		return "unix", strings.TrimPrefix(c.AdminBinding, "unix:")
+	}
+
+	return "tcp", c.AdminBinding
+}
+
+// GetAdminListener returns a listener for the admin server
+// obtained from the "AdminBinding" field. Connections from the addresses
+// not allowed by AllowFrom and DenyFrom are rejected.
+func (c *Config) GetAdminListener() (net.Listener, error) {
+	if len(c.AdminBinding) == 0 {
+		return nil, fmt.Errorf("admin server address is not set")
+	}
+
+	list, err := c.accessList()
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen(c.adminAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	return acl.NewListener(l, list, func(conn net.Conn) {
+		logger.WithFields(logging.Fields{"addr": conn.LocalAddr().String(), "peer.address": conn.RemoteAddr().String()}).Warn("Admin connection rejected by access list")
+	}), nil
+}
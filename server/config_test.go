@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestAdminListenerAccessList(t *testing.T) {
+	cfg := &Config{AdminBinding: "127.0.0.1:0", DenyFrom: []string{"127.0.0.0/8"}}
+
+	l, err := cfg.GetAdminListener()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			accepted <- c
+		}
+	}()
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// The rejected connection is closed by the server
+	c.SetReadDeadline(time.Now().Add(time.Second))
+
+	_, err = c.Read(make([]byte, 1))
+
+	if ne, ok := err.(net.Error); err == nil || (ok && ne.Timeout()) {
+		t.Fatalf("expected the connection to be closed, got %v", err)
+	}
+
+	select {
+	case <-accepted:
+		t.Fatal("denied connection was accepted")
+	default:
+	}
+}
+
+func TestAdminBindingValidation(t *testing.T) {
+	tests := []struct {
+		binding string
+		valid   bool
+	}{
+		{"127.0.0.1:9000", true},
+		{"[::1]:9000", true},
+		{"localhost:9000", true},
+		{"unix:/run/app-admin.sock", true},
+		{"0.0.0.0:9000", false},
+		{":9000", false},
+		{"10.0.0.1:9000", false},
+		{"admin.example.org:9000", false},
+		{"127.0.0.1", false},
+	}
+
+	for idx, tt := range tests {
+		cfg := &Config{Bindings: []string{"127.0.0.1"}, Port: 1, GatewayPort: 2, GRPCSocketPath: "/run/test.sock", AdminBinding: tt.binding}
+
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("idx == %d: %s: got invalid result: want valid = %t, got %v", idx, tt.binding, tt.valid, err)
+		}
+	}
+}
+
+func TestLogResponsesDecider(t *testing.T) {
+	tests := []struct {
+		enabled bool
+		methods []string
+		method  string
+		want    bool
+	}{
+		{false, nil, "/pkg.Svc/Get", false},
+		{false, []string{"/pkg.Svc/Get"}, "/pkg.Svc/Get", false},
+		{true, nil, "/pkg.Svc/Get", true},
+		{true, []string{"/pkg.Svc/Get"}, "/pkg.Svc/Get", true},
+		{true, []string{"/pkg.Svc/Get"}, "/pkg.Svc/GetAll", false},
+		{true, []string{"/pkg.Svc/Get"}, "/pkg.Svc/List", false},
+		{true, []string{"/pkg.Svc/"}, "/pkg.Svc/List", true},
+		{true, []string{"/pkg.Svc/"}, "/pkg.SvcAdmin/List", false},
+		{true, []string{"/pkg.Svc"}, "/pkg.Svc/List", false},
+		{true, []string{"/other.Svc/", "/pkg.Svc/Get"}, "/pkg.Svc/Get", true},
+	}
+
+	for idx, tt := range tests {
+		cfg := &Config{LogResponses: tt.enabled, LogResponsesMethods: tt.methods}
+
+		if got := cfg.logResponsesDecider()(tt.method); got != tt.want {
+			t.Errorf("idx == %d: %s with %v: got %t, want %t", idx, tt.method, tt.methods, got, tt.want)
+		}
+	}
+}
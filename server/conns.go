@@ -0,0 +1,336 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/utils"
+
+	"google.golang.org/grpc/stats"
+)
+
+// ConnInfo describes an open client connection of the gRPC server.
+type ConnInfo struct {
+	ID       uint64    `json:"id"`
+	Peer     string    `json:"peer"`
+	Listener string    `json:"listener"`
+	Identity string    `json:"identity,omitempty"`
+	OpenedAt time.Time `json:"opened_at"`
+
+	RPCs      int64 `json:"rpcs"`
+	BytesRecv int64 `json:"bytes_recv"`
+	BytesSent int64 `json:"bytes_sent"`
+
+	// Closable reports whether the connection can be closed using
+	// [Server.CloseConnection]. Connections accepted on the Unix socket
+	// cannot be closed.
+	Closable bool `json:"closable"`
+}
+
+type trackedConn struct {
+	id       uint64
+	peer     string
+	listener string
+	openedAt time.Time
+
+	// Set on the first call
+	identity atomic.Pointer[string]
+
+	rpcs      atomic.Int64
+	bytesRecv atomic.Int64
+	bytesSent atomic.Int64
+}
+
+func (c *trackedConn) info() ConnInfo {
+	ci := ConnInfo{
+		ID:        c.id,
+		Peer:      c.peer,
+		Listener:  c.listener,
+		OpenedAt:  c.openedAt,
+		RPCs:      c.rpcs.Load(),
+		BytesRecv: c.bytesRecv.Load(),
+		BytesSent: c.bytesSent.Load(),
+	}
+
+	if v := c.identity.Load(); v != nil {
+		ci.Identity = *v
+	}
+
+	return ci
+}
+
+func (c *trackedConn) fields() logging.Fields {
+	ci := c.info()
+
+	fields := logging.Fields{
+		"conn.id":       ci.ID,
+		"peer.address":  ci.Peer,
+		"conn.listener": ci.Listener,
+	}
+
+	if len(ci.Identity) > 0 {
+		fields["peer.identity"] = ci.Identity
+	}
+
+	return fields
+}
+
+type connKey struct{}
+
+// connTracker is a [stats.Handler] that keeps track of the open connections
+// of the gRPC server and counts their calls and traffic.
+//
+// The raw connections accepted by the listeners wrapped using the listener() method
+// are also kept to be able to close them.
+type connTracker struct {
+	mu sync.Mutex
+
+	lastID uint64
+	conns  map[uint64]*trackedConn
+
+	// "local address|remote address" -> raw connection
+	raw map[string]net.Conn
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{
+		conns: make(map[uint64]*trackedConn),
+		raw:   make(map[string]net.Conn),
+	}
+}
+
+func connAddrKey(local, remote net.Addr) string {
+	return local.String() + "|" + remote.String()
+}
+
+// TagConn implements the [stats.Handler] interface.
+func (t *connTracker) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastID++
+
+	c := &trackedConn{
+		id:       t.lastID,
+		peer:     info.RemoteAddr.String(),
+		listener: info.LocalAddr.String(),
+		openedAt: time.Now(),
+	}
+
+	t.conns[c.id] = c
+
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// HandleConn implements the [stats.Handler] interface.
+func (t *connTracker) HandleConn(ctx context.Context, s stats.ConnStats) {
+	c, ok := ctx.Value(connKey{}).(*trackedConn)
+	if !ok {
+		return
+	}
+
+	switch s.(type) {
+	case *stats.ConnBegin:
+		logger.WithFields(c.fields()).Info("Client connected")
+	case *stats.ConnEnd:
+		t.mu.Lock()
+
+		delete(t.conns, c.id)
+
+		t.mu.Unlock()
+
+		ci := c.info()
+
+		logger.WithFields(c.fields()).WithFields(logging.Fields{
+			"conn.duration": time.Since(ci.OpenedAt).String(),
+			"conn.rpcs":     ci.RPCs,
+			"conn.recv":     ci.BytesRecv,
+			"conn.sent":     ci.BytesSent,
+		}).Info("Client disconnected")
+	}
+}
+
+// TagRPC implements the [stats.Handler] interface.
+func (t *connTracker) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	if c, ok := ctx.Value(connKey{}).(*trackedConn); ok && c.identity.Load() == nil {
+		// The TLS properties are known only after the handshake
+		identity, _ := utils.PeerIdentity(ctx)
+
+		c.identity.CompareAndSwap(nil, &identity)
+	}
+
+	return ctx
+}
+
+// HandleRPC implements the [stats.Handler] interface.
+func (t *connTracker) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	c, ok := ctx.Value(connKey{}).(*trackedConn)
+	if !ok {
+		return
+	}
+
+	switch v := s.(type) {
+	case *stats.Begin:
+		c.rpcs.Add(1)
+	case *stats.InPayload:
+		c.bytesRecv.Add(int64(v.WireLength))
+	case *stats.OutPayload:
+		c.bytesSent.Add(int64(v.WireLength))
+	}
+}
+
+// listener wraps a given listener to keep the accepted raw connections.
+func (t *connTracker) listener(l net.Listener) net.Listener {
+	return &trackingListener{Listener: l, tracker: t}
+}
+
+// list returns the open connections sorted by ID.
+func (t *connTracker) list() []ConnInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conns := make([]ConnInfo, 0, len(t.conns))
+
+	for _, c := range t.conns {
+		ci := c.info()
+
+		_, ci.Closable = t.raw[connAddrKey(addrString(ci.Listener), addrString(ci.Peer))]
+
+		conns = append(conns, ci)
+	}
+
+	sort.Slice(conns, func(i, j int) bool { return conns[i].ID < conns[j].ID })
+
+	return conns
+}
+
+// close closes the connection with a given ID.
+func (t *connTracker) close(id uint64) error {
+	t.mu.Lock()
+
+	c, ok := t.conns[id]
+	if !ok {
+		t.mu.Unlock()
+
+		return fmt.Errorf("connection not found: %d", id)
+	}
+
+	raw, ok := t.raw[connAddrKey(addrString(c.listener), addrString(c.peer))]
+
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("connection cannot be closed: %d", id)
+	}
+
+	logger.WithFields(c.fields()).Warn("Closing client connection")
+
+	return raw.Close()
+}
+
+type trackingListener struct {
+	net.Listener
+
+	tracker *connTracker
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	key := connAddrKey(c.LocalAddr(), c.RemoteAddr())
+
+	l.tracker.mu.Lock()
+
+	l.tracker.raw[key] = c
+
+	l.tracker.mu.Unlock()
+
+	return &untrackingConn{Conn: c, tracker: l.tracker, key: key}, nil
+}
+
+// untrackingConn removes the connection from the tracker when it is closed.
+type untrackingConn struct {
+	net.Conn
+
+	tracker *connTracker
+	key     string
+	once    sync.Once
+}
+
+func (c *untrackingConn) Close() error {
+	c.once.Do(func() {
+		c.tracker.mu.Lock()
+
+		delete(c.tracker.raw, c.key)
+
+		c.tracker.mu.Unlock()
+	})
+
+	return c.Conn.Close()
+}
+
+// addrString is a [net.Addr] with a given string representation.
+type addrString string
+
+func (a addrString) Network() string { return "" }
+func (a addrString) String() string  { return string(a) }
+
+// Connections returns the list of the open client connections.
+func (s *Server) Connections() []ConnInfo {
+	return s.conns.list()
+}
+
+// CloseConnection closes the client connection with a given ID
+// (see [Server.Connections]).
+func (s *Server) CloseConnection(id uint64) error {
+	return s.conns.close(id)
+}
+
+// connectionsHandler returns the list of the open client connections (GET)
+// or closes the connection specified by the "id" parameter (POST).
+func (s *Server) connectionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid 'id' value: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		if err := s.CloseConnection(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	default:
+		w.Header().Set("Allow", "GET, POST")
+
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(s.Connections()); err != nil {
+		logger.WithError(err).Error("Cannot encode connection list")
+	}
+}
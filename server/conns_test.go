@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestConnectionTracking(t *testing.T) {
+	s := newTestServer(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer(grpc.StatsHandler(s.conns))
+
+	grpc_health.RegisterHealthServer(srv, health.NewServer())
+
+	go srv.Serve(s.conns.listener(l))
+
+	defer srv.Stop()
+
+	cc, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := grpc_health.NewHealthClient(cc).Check(context.Background(), new(grpc_health.HealthCheckRequest)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// GET /debug/connections
+	w := httptest.NewRecorder()
+
+	s.connectionsHandler(w, httptest.NewRequest(http.MethodGet, "/debug/connections", nil))
+
+	var conns []ConnInfo
+
+	if err := json.NewDecoder(w.Body).Decode(&conns); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conns) != 1 {
+		t.Fatalf("got %d connections, want 1", len(conns))
+	}
+
+	ci := conns[0]
+
+	if ci.RPCs != 2 || ci.BytesRecv == 0 || ci.BytesSent == 0 || !ci.Closable || ci.Listener != l.Addr().String() {
+		t.Fatalf("got invalid connection info: %+v", ci)
+	}
+
+	// POST with invalid parameters
+	for id, want := range map[string]int{"abc": http.StatusBadRequest, "1000": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+
+		s.connectionsHandler(w, postForm(url.Values{"id": {id}}))
+
+		if w.Code != want {
+			t.Errorf("id = %q: got status %d, want %d", id, w.Code, want)
+		}
+	}
+
+	// Close the connection
+	w = httptest.NewRecorder()
+
+	s.connectionsHandler(w, postForm(url.Values{"id": {strconv.FormatUint(ci.ID, 10)}}))
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got invalid status: %d", w.Code)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for len(s.Connections()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("the connection is still tracked after closing: %+v", s.Connections())
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := s.CloseConnection(ci.ID); err == nil {
+		t.Fatalf("closed connection can be closed again")
+	}
+}
+
+func postForm(v url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/debug/connections", strings.NewReader(v.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
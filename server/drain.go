@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// DrainHook is a function that is called each time the drain mode is changed.
+type DrainHook func(enabled bool, retryAfter time.Duration, buckets []string)
+
+// drainState holds the drain mode properties and the mapping of gRPC services
+// to the buckets they were registered from.
+type drainState struct {
+	mu sync.RWMutex
+
+	enabled    bool
+	retryAfter time.Duration
+	buckets    map[string]struct{}
+
+	// gRPC service name -> bucket names
+	services map[string][]string
+
+	hooks []DrainHook
+}
+
+func newDrainState() *drainState {
+	return &drainState{
+		buckets:  make(map[string]struct{}),
+		services: make(map[string][]string),
+	}
+}
+
+// check reports whether new calls of a given method should be rejected.
+func (d *drainState) check(fullMethod string) (time.Duration, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.enabled {
+		return 0, false
+	}
+
+	return d.retryAfter, d.serviceDrainedLocked(path.Dir(fullMethod)[1:])
+}
+
+// bucketsOf returns the buckets a given gRPC service was registered from.
+func (d *drainState) bucketsOf(name string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.services[name]
+}
+
+func (d *drainState) serviceDrainedLocked(name string) bool {
+	for _, bucket := range d.services[name] {
+		if _, ok := d.buckets[bucket]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetDrain enables or disables the drain mode.
+//
+// In drain mode, the health service reports NOT_SERVING, and new calls of the services
+// from the given buckets are rejected with the Unavailable code and a retry hint equal
+// to retryAfter. In-flight calls are not affected. If no buckets are specified,
+// all buckets served by the server are drained.
+func (s *Server) SetDrain(enabled bool, retryAfter time.Duration, buckets ...string) {
+	if len(buckets) == 0 {
+		buckets = s.buckets
+	}
+
+	s.drain.mu.Lock()
+
+	s.drain.enabled = enabled
+	s.drain.retryAfter = retryAfter
+	s.drain.buckets = make(map[string]struct{})
+
+	if enabled {
+		for _, b := range buckets {
+			s.drain.buckets[b] = struct{}{}
+		}
+	}
+
+	s.updateHealthLocked()
+
+	hooks := s.drain.hooks
+
+	s.drain.mu.Unlock()
+
+	logger.WithFields(logging.Fields{"enabled": enabled, "retry_after": retryAfter, "buckets": buckets}).Warn("Drain mode changed")
+
+	for _, fn := range hooks {
+		fn(enabled, retryAfter, buckets)
+	}
+}
+
+// Draining reports whether the server is in drain mode.
+func (s *Server) Draining() bool {
+	s.drain.mu.RLock()
+	defer s.drain.mu.RUnlock()
+
+	return s.drain.enabled
+}
+
+// OnDrain adds a function that is called each time the drain mode is changed.
+// It should be called before the server starts.
+func (s *Server) OnDrain(fn DrainHook) {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+
+	s.drain.hooks = append(s.drain.hooks, fn)
+}
+
+// ToggleDrainOnSignal starts a goroutine that toggles the drain mode of all served buckets
+// each time one of the given signals is received. The goroutine stops when the context is done.
+func (s *Server) ToggleDrainOnSignal(ctx context.Context, retryAfter time.Duration, sig ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+
+	signal.Notify(ch, sig...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				s.SetDrain(!s.Draining(), retryAfter)
+			}
+		}
+	}()
+}
+
+// registerServices registers the health service and the services of the served buckets
+// on the gRPC server and remembers which buckets each gRPC service belongs to.
+func (s *Server) registerServices() {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+
+	grpc_health.RegisterHealthServer(s.grpcServer, s.health)
+
+	// Service name -> bucket names
+	svcBuckets := make(map[string][]string)
+
+	for _, bucket := range s.buckets {
+		for _, svc := range Services(bucket) {
+			svcBuckets[svc.Name()] = append(svcBuckets[svc.Name()], bucket)
+		}
+	}
+
+	for _, svc := range Services(s.buckets...) {
+		if _, ok := svcBuckets[svc.Name()]; !ok {
+			// Already registered from another bucket
+			continue
+		}
+
+		logger.Info("Registering service: ", svc.Name())
+
+		before := s.grpcServer.GetServiceInfo()
+
+		svc.RegisterGRPC(s.grpcServer)
+
+		for name := range s.grpcServer.GetServiceInfo() {
+			if _, ok := before[name]; !ok {
+				s.drain.services[name] = svcBuckets[svc.Name()]
+			}
+		}
+
+		delete(svcBuckets, svc.Name())
+	}
+
+	s.updateHealthLocked()
+}
+
+// updateHealthLocked sets the serving status of the server and its services
+// according to the drain mode.
+func (s *Server) updateHealthLocked() {
+	if s.drain.enabled {
+		s.health.SetServingStatus("", grpc_health.HealthCheckResponse_NOT_SERVING)
+	} else {
+		s.health.SetServingStatus("", grpc_health.HealthCheckResponse_SERVING)
+	}
+
+	for name := range s.drain.services {
+		if s.drain.enabled && s.drain.serviceDrainedLocked(name) {
+			s.health.SetServingStatus(name, grpc_health.HealthCheckResponse_NOT_SERVING)
+		} else {
+			s.health.SetServingStatus(name, grpc_health.HealthCheckResponse_SERVING)
+		}
+	}
+}
+
+type drainInfo struct {
+	Enabled    bool     `json:"enabled"`
+	RetryAfter string   `json:"retry_after"`
+	Buckets    []string `json:"buckets"`
+}
+
+// drainHandler returns the drain mode state (GET) or changes it (POST).
+//
+// POST parameters: "enabled" (boolean), "retry_after" (duration, e.g. "30s")
+// and "bucket" (can be repeated).
+func (s *Server) drainHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		enabled, err := strconv.ParseBool(r.Form.Get("enabled"))
+		if err != nil {
+			http.Error(w, "invalid 'enabled' value: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		var retryAfter time.Duration
+
+		if v := r.Form.Get("retry_after"); len(v) > 0 {
+			if retryAfter, err = time.ParseDuration(v); err != nil {
+				http.Error(w, "invalid 'retry_after' value: "+err.Error(), http.StatusBadRequest)
+
+				return
+			}
+		}
+
+		s.SetDrain(enabled, retryAfter, r.Form["bucket"]...)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	s.drain.mu.RLock()
+
+	info := drainInfo{
+		Enabled:    s.drain.enabled,
+		RetryAfter: s.drain.retryAfter.String(),
+		Buckets:    make([]string, 0, len(s.drain.buckets)),
+	}
+
+	for b := range s.drain.buckets {
+		info.Buckets = append(info.Buckets, b)
+	}
+
+	s.drain.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(&info); err != nil {
+		logger.WithError(err).Error("Cannot encode drain mode state")
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	grpc_runtime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// testService registers an empty gRPC service with a given name.
+type testService string
+
+func (s testService) Name() string { return string(s) }
+
+func (s testService) RegisterGRPC(srv *grpc.Server) {
+	srv.RegisterService(&grpc.ServiceDesc{ServiceName: string(s), HandlerType: (*interface{})(nil)}, struct{}{})
+}
+
+func (s testService) RegisterGW(*grpc_runtime.ServeMux, string, []grpc.DialOption) {}
+
+func newTestServer(t *testing.T) *Server {
+	cfg := &Config{Bindings: []string{"127.0.0.1"}, Port: 1, GatewayPort: 2, GRPCSocketPath: "/run/test.sock"}
+
+	s, err := NewServer(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestDrainHealthStatus(t *testing.T) {
+	Register(testService("test.DrainedService"), WithServiceBucket("drain-a"))
+	Register(testService("test.ServingService"), WithServiceBucket("drain-b"))
+
+	s := newTestServer(t)
+
+	s.SetServiceBuckets("drain-a", "drain-b")
+
+	s.registerServices()
+
+	var hooked []string
+
+	s.OnDrain(func(enabled bool, retryAfter time.Duration, buckets []string) {
+		hooked = buckets
+	})
+
+	healthStatus := func(name string) grpc_health.HealthCheckResponse_ServingStatus {
+		resp, err := s.health.Check(context.Background(), &grpc_health.HealthCheckRequest{Service: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return resp.Status
+	}
+
+	s.SetDrain(true, 2*time.Second, "drain-a")
+
+	want := map[string]grpc_health.HealthCheckResponse_ServingStatus{
+		"":                    grpc_health.HealthCheckResponse_NOT_SERVING,
+		"test.DrainedService": grpc_health.HealthCheckResponse_NOT_SERVING,
+		"test.ServingService": grpc_health.HealthCheckResponse_SERVING,
+	}
+
+	for name, st := range want {
+		if got := healthStatus(name); got != st {
+			t.Errorf("%q: got %s, want %s", name, got, st)
+		}
+	}
+
+	if d, ok := s.drain.check("/test.DrainedService/Get"); !ok || d != 2*time.Second {
+		t.Errorf("the drained service is not rejected: %s, %t", d, ok)
+	}
+
+	if _, ok := s.drain.check("/test.ServingService/Get"); ok {
+		t.Errorf("the serving service is rejected")
+	}
+
+	if len(hooked) != 1 || hooked[0] != "drain-a" {
+		t.Errorf("got invalid hook buckets: %v", hooked)
+	}
+
+	s.SetDrain(false, 0)
+
+	for name := range want {
+		if got := healthStatus(name); got != grpc_health.HealthCheckResponse_SERVING {
+			t.Errorf("%q: got %s after the drain mode is disabled", name, got)
+		}
+	}
+}
+
+func TestDrainHandler(t *testing.T) {
+	s := newTestServer(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/drain", strings.NewReader("enabled=true&retry_after=30s"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+
+	s.drainHandler(w, r)
+
+	var info drainInfo
+
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.Enabled || info.RetryAfter != "30s" || len(info.Buckets) != 1 || info.Buckets[0] != defaultServiceBucket {
+		t.Fatalf("got invalid drain state: %+v", info)
+	}
+
+	if !s.Draining() {
+		t.Fatalf("the drain mode is not enabled")
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/drain", strings.NewReader("enabled=maybe"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w = httptest.NewRecorder()
+
+	s.drainHandler(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got invalid status for the malformed request: %d", w.Code)
+	}
+}
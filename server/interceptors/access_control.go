@@ -0,0 +1,58 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/status"
+	"github.com/0xef53/go-grpc/utils"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+)
+
+// AccessDecider is a function that decides whether a client with a given address
+// is allowed to call a given method.
+type AccessDecider func(fullMethod string, addr net.Addr) bool
+
+// AccessControlUnaryServerInterceptor returns a unary server interceptor that rejects calls
+// with the PermissionDenied code if the decider does not allow the client address.
+// The address is obtained using [utils.ClientAddr].
+func AccessControlUnaryServerInterceptor(decider AccessDecider) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkAccess(ctx, info.FullMethod, decider); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AccessControlStreamServerInterceptor returns a stream server interceptor that rejects calls
+// with the PermissionDenied code if the decider does not allow the client address.
+// The address is obtained using [utils.ClientAddr].
+func AccessControlStreamServerInterceptor(decider AccessDecider) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkAccess(ss.Context(), info.FullMethod, decider); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func checkAccess(ctx context.Context, fullMethod string, decider AccessDecider) error {
+	addr, ok := utils.ClientAddr(ctx)
+	if !ok {
+		return nil
+	}
+
+	if decider(fullMethod, addr) {
+		return nil
+	}
+
+	logging.Extract(ctx).WithField("client.address", addr.String()).Warn("Access denied")
+
+	return status.Error(ctx, grpc_codes.PermissionDenied, "access denied")
+}
@@ -0,0 +1,101 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xef53/go-grpc/audit"
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/proto/message"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"google.golang.org/grpc"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// AuditDecider is a function that decides whether the call of a given method
+// should be written to the audit log.
+type AuditDecider func(fullMethod string) bool
+
+// AuditUnaryServerInterceptor returns a unary server interceptor that writes one audit record
+// per RPC call to a given sink. Request and response payloads are redacted according to
+// the properties of [options.FieldLogging].
+//
+// If decider is nil, all calls are written.
+func AuditUnaryServerInterceptor(sink audit.Sink, decider AuditDecider) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if decider != nil && !decider(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		rec := newAuditRecord(ctx, info.FullMethod, start, err)
+
+		rec.Request = tagsFromPayload(req)
+
+		if err == nil {
+			rec.Response = tagsFromPayload(resp)
+		}
+
+		if err := sink.Write(rec); err != nil {
+			logging.Extract(ctx).WithError(err).Error("Cannot write audit record")
+		}
+
+		return resp, err
+	}
+}
+
+// AuditStreamServerInterceptor returns a stream server interceptor that writes one audit record
+// per RPC stream to a given sink. Stream payloads are not captured.
+//
+// If decider is nil, all calls are written.
+func AuditStreamServerInterceptor(sink audit.Sink, decider AuditDecider) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if decider != nil && !decider(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		if err := sink.Write(newAuditRecord(ss.Context(), info.FullMethod, start, err)); err != nil {
+			logging.Extract(ss.Context()).WithError(err).Error("Cannot write audit record")
+		}
+
+		return err
+	}
+}
+
+func newAuditRecord(ctx context.Context, method string, start time.Time, err error) *audit.Record {
+	rec := audit.Record{
+		Time:     start,
+		Method:   method,
+		Caller:   audit.CallerFromContext(ctx),
+		Code:     grpc_status.Code(err).String(),
+		Duration: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+
+	// Set by the RequestIdentifier interceptor
+	if v, ok := grpc_ctxtags.Extract(ctx).Values()["request.uid"]; ok {
+		rec.RequestID, _ = v.(string)
+	}
+
+	if err != nil {
+		rec.Error = grpc_status.Convert(err).Message()
+	}
+
+	return &rec
+}
+
+func tagsFromPayload(v interface{}) map[string]interface{} {
+	if m, ok := v.(proto.Message); ok && m != nil {
+		return message.TagsFromMessage(m.ProtoReflect())
+	}
+
+	return nil
+}
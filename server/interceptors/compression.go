@@ -0,0 +1,55 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/0xef53/go-grpc/proto/method"
+
+	"google.golang.org/grpc"
+)
+
+// CompressionUnaryServerInterceptor returns a unary server interceptor that selects
+// the compressor for responses.
+//
+// The compressor is taken from the method option of type [options.MethodPolicy]
+// or, if not set there, the given default name is used. The compressor is applied
+// only if the client advertised its support. Otherwise, responses are compressed
+// in the same way as the request.
+func CompressionUnaryServerInterceptor(defaultName string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		setSendCompressor(ctx, info.FullMethod, defaultName)
+
+		return handler(ctx, req)
+	}
+}
+
+// CompressionStreamServerInterceptor returns a stream server interceptor that selects
+// the compressor for responses.
+//
+// The compressor is taken from the method option of type [options.MethodPolicy]
+// or, if not set there, the given default name is used. The compressor is applied
+// only if the client advertised its support. Otherwise, responses are compressed
+// in the same way as the request.
+func CompressionStreamServerInterceptor(defaultName string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		setSendCompressor(ss.Context(), info.FullMethod, defaultName)
+
+		return handler(srv, ss)
+	}
+}
+
+func setSendCompressor(ctx context.Context, fullMethod, defaultName string) {
+	name := method.Policy(fullMethod).GetCompression()
+
+	if len(name) == 0 {
+		name = defaultName
+	}
+
+	if len(name) == 0 {
+		return
+	}
+
+	// An error means that the client does not support the compressor
+	// or it is not registered. The request compressor is used in this case.
+	_ = grpc.SetSendCompressor(ctx, name)
+}
@@ -0,0 +1,49 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xef53/go-grpc/status"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+)
+
+// DrainDecider is a function that decides whether new calls of a given method
+// should be rejected. The returned duration is a retry hint for the clients.
+type DrainDecider func(fullMethod string) (time.Duration, bool)
+
+// DrainUnaryServerInterceptor returns a unary server interceptor that rejects new calls
+// with the Unavailable code and a retry hint (see [status.RetryInfo]) if the decider
+// reports that the method is being drained.
+func DrainUnaryServerInterceptor(decider DrainDecider) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if retryAfter, ok := decider(info.FullMethod); ok {
+			return nil, drainError(ctx, retryAfter)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// DrainStreamServerInterceptor returns a stream server interceptor that rejects new calls
+// with the Unavailable code and a retry hint (see [status.RetryInfo]) if the decider
+// reports that the method is being drained.
+func DrainStreamServerInterceptor(decider DrainDecider) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if retryAfter, ok := decider(info.FullMethod); ok {
+			return drainError(ss.Context(), retryAfter)
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func drainError(ctx context.Context, retryAfter time.Duration) error {
+	if retryAfter > 0 {
+		return status.Error(ctx, grpc_codes.Unavailable, "server is in drain mode", status.RetryInfo(retryAfter))
+	}
+
+	return status.Error(ctx, grpc_codes.Unavailable, "server is in drain mode")
+}
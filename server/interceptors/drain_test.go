@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xef53/go-grpc/status"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+func TestDrainRejection(t *testing.T) {
+	decider := func(fullMethod string) (time.Duration, bool) {
+		return 3 * time.Second, strings.HasPrefix(fullMethod, "/pkg.Drained/")
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	}
+
+	interceptor := DrainUnaryServerInterceptor(decider)
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Drained/Get"}, handler)
+
+	if code := grpc_status.Code(err); code != grpc_codes.Unavailable {
+		t.Fatalf("got invalid code: %s", code)
+	}
+
+	if d, ok := status.RetryDelay(err); !ok || d != 3*time.Second {
+		t.Fatalf("got invalid retry hint: %s (ok = %t)", d, ok)
+	}
+
+	if _, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Serving/Get"}, handler); err != nil {
+		t.Fatalf("the call of a non-drained service was rejected: %s", err)
+	}
+
+	err = DrainStreamServerInterceptor(decider)(nil, &testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/pkg.Drained/Watch"}, nil)
+
+	if code := grpc_status.Code(err); code != grpc_codes.Unavailable {
+		t.Fatalf("got invalid code for stream: %s", code)
+	}
+}
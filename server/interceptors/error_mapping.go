@@ -0,0 +1,45 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/status"
+
+	"google.golang.org/grpc"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+// ErrorMappingUnaryServerInterceptor returns a unary server interceptor that converts
+// domain errors returned by handlers to gRPC status errors according to the mappings
+// registered in the [status] package.
+//
+// The client only gets the message of the mapped error, the full error chain is logged.
+func ErrorMappingUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+
+		return resp, mapError(ctx, err)
+	}
+}
+
+// ErrorMappingStreamServerInterceptor returns a stream server interceptor that converts
+// domain errors returned by handlers to gRPC status errors according to the mappings
+// registered in the [status] package.
+//
+// The client only gets the message of the mapped error, the full error chain is logged.
+func ErrorMappingStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return mapError(ss.Context(), handler(srv, ss))
+	}
+}
+
+func mapError(ctx context.Context, err error) error {
+	mapped, ok := status.FromError(ctx, err)
+
+	if ok {
+		logging.Extract(ctx).WithContext(ctx).WithError(err).WithField("grpc.code", grpc_status.Code(mapped).String()).Info("Handler error mapped to gRPC status")
+	}
+
+	return mapped
+}
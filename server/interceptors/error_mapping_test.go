@@ -0,0 +1,69 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/status"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+func TestErrorMappingHidesErrorChain(t *testing.T) {
+	errGone := errors.New("object is gone")
+
+	status.RegisterError(errGone, grpc_codes.NotFound)
+
+	logger, hook := newTestLogger()
+
+	ctx := logging.ToContext(context.Background(), logger)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("cannot read /var/lib/objects/1: %w", errGone)
+	}
+
+	_, err := ErrorMappingUnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}, handler)
+
+	if st := grpc_status.Convert(err); st.Code() != grpc_codes.NotFound || st.Message() != "object is gone" {
+		t.Fatalf("got unexpected status: %v", st)
+	}
+
+	last := hook.LastEntry()
+
+	if last == nil || fmt.Sprint(last.Data["error"]) != "cannot read /var/lib/objects/1: object is gone" {
+		t.Fatalf("the full error chain is not logged: %v", last)
+	}
+}
+
+// validationErrors is not comparable
+type validationErrors []string
+
+func (e validationErrors) Error() string {
+	return strings.Join(e, "; ")
+}
+
+func TestErrorMappingUncomparableError(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, validationErrors{"name is empty", "age is negative"}
+	}
+
+	_, err := ErrorMappingUnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Create"}, handler)
+
+	if _, ok := err.(validationErrors); !ok {
+		t.Fatalf("got invalid error: want the handler error as is, got %T", err)
+	}
+
+	status.RegisterErrorType[validationErrors](grpc_codes.InvalidArgument)
+
+	_, err = ErrorMappingUnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Create"}, handler)
+
+	if st := grpc_status.Convert(err); st.Code() != grpc_codes.InvalidArgument || st.Message() != "name is empty; age is negative" {
+		t.Fatalf("got unexpected status: %v", st)
+	}
+}
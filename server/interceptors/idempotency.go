@@ -0,0 +1,157 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/0xef53/go-grpc/idempotency"
+	"github.com/0xef53/go-grpc/logging"
+	"github.com/0xef53/go-grpc/utils"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+// IdempotencyKeyHeader is the metadata key containing the idempotency key.
+// The gateway forwards the "X-Idempotency-Key" HTTP header under this name.
+const IdempotencyKeyHeader = "idempotency-key"
+
+// IdempotencyUnaryServerInterceptor returns a unary server interceptor that stores the response
+// or the error of the first execution of a request with the idempotency key in a given store
+// and replays it for the repeated requests with the same key within ttl.
+//
+// Transient errors (see [idempotency.IsTransient]) are not stored, so a retry
+// executes the request again. A repeated request with the same key but a different
+// payload is rejected with FailedPrecondition.
+//
+// Concurrent requests with the same key wait for the first one to finish.
+// Keys are scoped by the full method name and the caller: the identity of its
+// TLS certificate (see [utils.PeerIdentity]) or its IP address (see [utils.ClientAddr]).
+// Requests without the key and requests of callers that cannot be told apart,
+// e.g. the local ones via a Unix socket, are passed as is.
+func IdempotencyUnaryServerInterceptor(store idempotency.Store, ttl time.Duration) grpc.UnaryServerInterceptor {
+	var mu sync.Mutex
+
+	inflight := make(map[string]chan struct{})
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var key string
+
+		if md, ok := grpc_metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(IdempotencyKeyHeader); len(v) > 0 && len(v[0]) > 0 {
+				key = v[0]
+			}
+		}
+
+		if len(key) == 0 {
+			return handler(ctx, req)
+		}
+
+		logger := logging.Extract(ctx)
+
+		caller, ok := idempotencyScope(ctx)
+		if !ok {
+			logger.Warn("Cannot identify the caller, the idempotency key is ignored")
+
+			return handler(ctx, req)
+		}
+
+		key = info.FullMethod + "\x00" + caller + "\x00" + key
+
+		hash, err := idempotency.RequestHash(req)
+		if err != nil {
+			logger.WithError(err).Error("Cannot hash the idempotent request")
+
+			return handler(ctx, req)
+		}
+
+		// Only one request with the same key is processed at a time,
+		// the others wait for it to finish
+		for {
+			mu.Lock()
+
+			ch, ok := inflight[key]
+			if !ok {
+				inflight[key] = make(chan struct{})
+
+				mu.Unlock()
+
+				break
+			}
+
+			mu.Unlock()
+
+			select {
+			case <-ch:
+			case <-ctx.Done():
+				return nil, grpc_status.FromContextError(ctx.Err()).Err()
+			}
+		}
+
+		defer func() {
+			mu.Lock()
+
+			close(inflight[key])
+			delete(inflight, key)
+
+			mu.Unlock()
+		}()
+
+		if e, ok, err := store.Get(key); err != nil {
+			logger.WithError(err).Error("Cannot read idempotency store")
+		} else if ok {
+			if !e.Matches(hash) {
+				return nil, grpc_status.Error(grpc_codes.FailedPrecondition, "idempotency key is already used with a different request")
+			}
+
+			grpc.SetHeader(ctx, grpc_metadata.Pairs("idempotency-replayed", "true"))
+
+			logger.Info("Replaying the stored result of the idempotent request")
+
+			return e.Result()
+		}
+
+		resp, err := handler(ctx, req)
+
+		if idempotency.IsTransient(err) {
+			return resp, err
+		}
+
+		if e, err := idempotency.NewEntry(resp, err, ttl); err == nil {
+			e.RequestHash = hash
+
+			if err := store.Set(key, e); err != nil {
+				logger.WithError(err).Error("Cannot write idempotency store")
+			}
+		} else {
+			logger.WithError(err).Error("Cannot encode the result of the idempotent request")
+		}
+
+		return resp, err
+	}
+}
+
+// idempotencyScope returns the caller the idempotency keys of the current call
+// belong to. The second return value reports whether the caller was identified.
+func idempotencyScope(ctx context.Context) (string, bool) {
+	if identity, ok := utils.PeerIdentity(ctx); ok && len(identity) > 0 {
+		return "cn:" + identity, true
+	}
+
+	addr, ok := utils.ClientAddr(ctx)
+	if !ok || addr.Network() == "unix" {
+		return "", false
+	}
+
+	// The port differs between connections of the same client
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil || len(host) == 0 {
+		return "", false
+	}
+
+	return "ip:" + host, true
+}
@@ -0,0 +1,214 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xef53/go-grpc/idempotency"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_peer "google.golang.org/grpc/peer"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func idempotentContext(key, identity string) context.Context {
+	return idempotentPeerContext(key, identity, &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 5555})
+}
+
+func idempotentPeerContext(key, identity string, addr net.Addr) context.Context {
+	ctx := grpc_metadata.NewIncomingContext(context.Background(), grpc_metadata.Pairs(IdempotencyKeyHeader, key))
+
+	p := &grpc_peer.Peer{Addr: addr}
+
+	if len(identity) > 0 {
+		state := tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: identity}}},
+		}
+
+		p.AuthInfo = credentials.TLSInfo{State: state}
+	}
+
+	return grpc_peer.NewContext(ctx, p)
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	interceptor := IdempotencyUnaryServerInterceptor(idempotency.NewMemoryStore(0), time.Minute)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Create"}
+
+	var calls atomic.Int32
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.Int32(calls.Add(1)), nil
+	}
+
+	tests := []struct {
+		ctx       context.Context
+		req       proto.Message
+		wantCode  grpc_codes.Code
+		wantReply int32
+	}{
+		{idempotentContext("k1", "alice"), wrapperspb.String("a"), grpc_codes.OK, 1},
+		// Replayed
+		{idempotentContext("k1", "alice"), wrapperspb.String("a"), grpc_codes.OK, 1},
+		// The same key with a different payload
+		{idempotentContext("k1", "alice"), wrapperspb.String("b"), grpc_codes.FailedPrecondition, 0},
+		// The same key from another caller
+		{idempotentContext("k1", "bob"), wrapperspb.String("a"), grpc_codes.OK, 2},
+		// Without the key
+		{context.Background(), wrapperspb.String("a"), grpc_codes.OK, 3},
+	}
+
+	for idx, tt := range tests {
+		resp, err := interceptor(tt.ctx, tt.req, info, handler)
+
+		if code := grpc_status.Code(err); code != tt.wantCode {
+			t.Fatalf("got invalid code (idx == %d): %s", idx, code)
+		}
+
+		if err == nil && resp.(*wrapperspb.Int32Value).GetValue() != tt.wantReply {
+			t.Fatalf("got invalid reply (idx == %d): want %d, got %v", idx, tt.wantReply, resp)
+		}
+	}
+}
+
+func TestIdempotencyCallerScope(t *testing.T) {
+	interceptor := IdempotencyUnaryServerInterceptor(idempotency.NewMemoryStore(0), time.Minute)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Create"}
+
+	var calls atomic.Int32
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.Int32(calls.Add(1)), nil
+	}
+
+	unixAddr := &net.UnixAddr{Name: "@app.sock", Net: "unix"}
+
+	tests := []struct {
+		ctx       context.Context
+		wantReply int32
+	}{
+		{idempotentPeerContext("k1", "", &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1111}), 1},
+		// The same client on another connection
+		{idempotentPeerContext("k1", "", &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 2222}), 1},
+		// Another plaintext client with the same key
+		{idempotentPeerContext("k1", "", &net.TCPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 1111}), 2},
+		// The local clients cannot be told apart, so nothing is stored
+		{idempotentPeerContext("k1", "", unixAddr), 3},
+		{idempotentPeerContext("k1", "", unixAddr), 4},
+	}
+
+	for idx, tt := range tests {
+		resp, err := interceptor(tt.ctx, wrapperspb.String("a"), info, handler)
+		if err != nil {
+			t.Fatalf("unexpected error (idx == %d): %s", idx, err)
+		}
+
+		if got := resp.(*wrapperspb.Int32Value).GetValue(); got != tt.wantReply {
+			t.Fatalf("got invalid reply (idx == %d): want %d, got %d", idx, tt.wantReply, got)
+		}
+	}
+}
+
+func TestIdempotencyConcurrentRequests(t *testing.T) {
+	interceptor := IdempotencyUnaryServerInterceptor(idempotency.NewMemoryStore(0), time.Minute)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Create"}
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-release
+
+		return wrapperspb.Int32(calls.Add(1)), nil
+	}
+
+	var wg sync.WaitGroup
+
+	replies := make([]int32, 5)
+
+	for i := range replies {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := interceptor(idempotentContext("k1", ""), wrapperspb.String("a"), info, handler)
+			if err != nil {
+				t.Errorf("got unexpected error: %s", err)
+
+				return
+			}
+
+			replies[i] = resp.(*wrapperspb.Int32Value).GetValue()
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	close(release)
+
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("the handler was called %d times", n)
+	}
+
+	for idx, v := range replies {
+		if v != 1 {
+			t.Fatalf("got invalid reply (idx == %d): %d", idx, v)
+		}
+	}
+}
+
+func TestIdempotencyTransientError(t *testing.T) {
+	interceptor := IdempotencyUnaryServerInterceptor(idempotency.NewMemoryStore(0), time.Minute)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Create"}
+
+	results := []error{
+		grpc_status.Error(grpc_codes.Unavailable, "backend is down"),
+		context.DeadlineExceeded,
+		grpc_status.Error(grpc_codes.AlreadyExists, "exists"),
+		nil,
+	}
+
+	var calls atomic.Int32
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		err := results[calls.Add(1)-1]
+		if err != nil {
+			return nil, err
+		}
+
+		return wrapperspb.String("created"), nil
+	}
+
+	// Transient errors are not stored, the definitive error is replayed
+	for _, want := range []grpc_codes.Code{grpc_codes.Unavailable, grpc_codes.Unknown, grpc_codes.AlreadyExists, grpc_codes.AlreadyExists} {
+		_, err := interceptor(idempotentContext("k1", ""), wrapperspb.String("a"), info, handler)
+
+		if code := grpc_status.Code(err); code != want {
+			t.Fatalf("got invalid code: want %s, got %s", want, code)
+		}
+	}
+
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("the handler was called %d times", n)
+	}
+}
@@ -2,25 +2,138 @@ package interceptors
 
 import (
 	"context"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
 
-	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
 	"google.golang.org/grpc"
+	grpc_status "google.golang.org/grpc/status"
 )
 
+// LogDecider is a function that decides whether the details of a given method call
+// should be logged.
+type LogDecider func(fullMethod string) bool
+
+// LogOption is a common type for optional parameters of the request logging interceptors.
+type LogOption func(*logOptions)
+
+type logOptions struct {
+	responseDecider LogDecider
+	messageDecider  LogDecider
+}
+
+// WithResponseLogging enables logging of response tags, status code and duration
+// for the methods accepted by decider. If decider is nil, responses of all methods are logged.
+//
+// Response tags are extracted according to the properties of [options.FieldLogging]
+// and prefixed with "grpc.response.".
+func WithResponseLogging(decider LogDecider) LogOption {
+	return func(o *logOptions) {
+		if decider == nil {
+			decider = func(string) bool { return true }
+		}
+
+		o.responseDecider = decider
+	}
+}
+
+// WithStreamMessageLogging enables logging of the tags of each message sent or received
+// over the streams of the methods accepted by decider. If decider is nil, messages of all
+// streams are logged.
+func WithStreamMessageLogging(decider LogDecider) LogOption {
+	return func(o *logOptions) {
+		if decider == nil {
+			decider = func(string) bool { return true }
+		}
+
+		o.messageDecider = decider
+	}
+}
+
+func newLogOptions(opts ...LogOption) *logOptions {
+	o := new(logOptions)
+
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	return o
+}
+
+func (o *logOptions) shouldLogResponse(fullMethod string) bool {
+	return o.responseDecider != nil && o.responseDecider(fullMethod)
+}
+
+func (o *logOptions) shouldLogMessages(fullMethod string) bool {
+	return o.messageDecider != nil && o.messageDecider(fullMethod)
+}
+
 // LogRequestUnaryServerInterceptor returns a unary server interceptor that logs details of gRPC request.
-func LogRequestUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+func LogRequestUnaryServerInterceptor(opts ...LogOption) grpc.UnaryServerInterceptor {
+	o := newLogOptions(opts...)
+
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		ctxlogrus.Extract(ctx).WithContext(ctx).Infof("GRPC Request: %s", info.FullMethod)
+		logging.Extract(ctx).WithContext(ctx).Infof("GRPC Request: %s", info.FullMethod)
+
+		if !o.shouldLogResponse(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		fields := logging.Fields{
+			"grpc.code":     grpc_status.Code(err).String(),
+			"grpc.duration": time.Since(start),
+		}
+
+		if err == nil {
+			for k, v := range tagsFromPayload(resp) {
+				fields["grpc.response."+k] = v
+			}
 
-		return handler(ctx, req)
+			logging.Extract(ctx).WithContext(ctx).WithFields(fields).Infof("GRPC Response: %s", info.FullMethod)
+		} else {
+			logging.Extract(ctx).WithContext(ctx).WithFields(fields).WithError(err).Errorf("GRPC Request failed: %s", info.FullMethod)
+		}
+
+		return resp, err
 	}
 }
 
 // LogRequestStreamServerInterceptor returns a stream server interceptor that logs details of gRPC request.
-func LogRequestStreamServerInterceptor() grpc.StreamServerInterceptor {
+//
+// The stream is wrapped to count sent and received messages and bytes. Once the stream ends,
+// a completion line with the final status, duration and counters is logged.
+func LogRequestStreamServerInterceptor(opts ...LogOption) grpc.StreamServerInterceptor {
+	o := newLogOptions(opts...)
+
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		ctxlogrus.Extract(ss.Context()).WithContext(ss.Context()).Infof("GRPC Request: %s", info.FullMethod)
+		ctx := ss.Context()
+
+		logging.Extract(ctx).WithContext(ctx).Infof("GRPC Request: %s", info.FullMethod)
+
+		start := time.Now()
+
+		stream := &accountedServerStream{
+			ServerStream: ss,
+			logMessages:  o.shouldLogMessages(info.FullMethod),
+		}
+
+		err := handler(srv, stream)
+
+		fields := stream.fields()
+
+		fields["grpc.code"] = grpc_status.Code(err).String()
+		fields["grpc.duration"] = time.Since(start)
+
+		if err == nil {
+			logging.Extract(ctx).WithContext(ctx).WithFields(fields).Infof("GRPC Stream completed: %s", info.FullMethod)
+		} else {
+			logging.Extract(ctx).WithContext(ctx).WithFields(fields).WithError(err).Errorf("GRPC Stream failed: %s", info.FullMethod)
+		}
 
-		return handler(srv, ss)
+		return err
 	}
 }
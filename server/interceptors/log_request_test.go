@@ -0,0 +1,89 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func newTestLogger() (logging.Logger, *logtest.Hook) {
+	l, hook := logtest.NewNullLogger()
+
+	return logging.NewLogrusLogger(log.NewEntry(l)), hook
+}
+
+func TestLogRequestResponseLogging(t *testing.T) {
+	decider := func(fullMethod string) bool { return fullMethod == "/pkg.Svc/Get" }
+
+	tests := []struct {
+		method     string
+		err        error
+		wantLines  int
+		wantLevel  log.Level
+		wantCode   string
+		wantFields map[string]interface{}
+	}{
+		{"/pkg.Svc/Get", nil, 2, log.InfoLevel, "OK", map[string]interface{}{"grpc.response.value": "reply"}},
+		{"/pkg.Svc/Get", grpc_status.Error(grpc_codes.NotFound, "not found"), 2, log.ErrorLevel, "NotFound", nil},
+		{"/pkg.Svc/List", nil, 1, log.InfoLevel, "", nil},
+	}
+
+	for _, tt := range tests {
+		logger, hook := newTestLogger()
+
+		ctx := logging.ToContext(context.Background(), logger)
+
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tt.err != nil {
+				return nil, tt.err
+			}
+
+			return wrapperspb.String("reply"), nil
+		}
+
+		_, err := LogRequestUnaryServerInterceptor(WithResponseLogging(decider))(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: unexpected error: %v", tt.method, err)
+		}
+
+		entries := hook.AllEntries()
+
+		if len(entries) != tt.wantLines {
+			t.Fatalf("%s: got %d log lines, want %d", tt.method, len(entries), tt.wantLines)
+		}
+
+		if tt.wantLines == 1 {
+			continue
+		}
+
+		last := entries[len(entries)-1]
+
+		if last.Level != tt.wantLevel {
+			t.Errorf("%s: got level %s, want %s", tt.method, last.Level, tt.wantLevel)
+		}
+
+		if code := last.Data["grpc.code"]; code != tt.wantCode {
+			t.Errorf("%s: got code %v, want %s", tt.method, code, tt.wantCode)
+		}
+
+		if _, ok := last.Data["grpc.duration"]; !ok {
+			t.Errorf("%s: no duration field", tt.method)
+		}
+
+		for k, v := range tt.wantFields {
+			if last.Data[k] != v {
+				t.Errorf("%s: got %s = %v, want %v", tt.method, k, last.Data[k], v)
+			}
+		}
+	}
+}
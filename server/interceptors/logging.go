@@ -0,0 +1,100 @@
+package interceptors
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+// LoggerUnaryServerInterceptor returns a unary server interceptor that adds a given logger
+// to the context and logs the completion of each call.
+//
+// The logger can be obtained in handlers and subsequent interceptors using [logging.Extract].
+func LoggerUnaryServerInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		ctx = newLoggerForCall(ctx, logger, info.FullMethod, start)
+
+		resp, err := handler(ctx, req)
+
+		code := grpc_status.Code(err)
+
+		entry := logging.Extract(ctx).WithField("grpc.code", code.String()).WithField("grpc.time_ms", durationToMilliseconds(time.Since(start)))
+
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+
+		logByCode(entry, code, "finished unary call with code "+code.String())
+
+		return resp, err
+	}
+}
+
+// LoggerStreamServerInterceptor returns a stream server interceptor that adds a given logger
+// to the context and logs the completion of each call.
+//
+// The logger can be obtained in handlers and subsequent interceptors using [logging.Extract].
+func LoggerStreamServerInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		stream := grpc_middleware.WrapServerStream(ss)
+
+		stream.WrappedContext = newLoggerForCall(ss.Context(), logger, info.FullMethod, start)
+
+		err := handler(srv, stream)
+
+		code := grpc_status.Code(err)
+
+		entry := logging.Extract(stream.WrappedContext).WithField("grpc.code", code.String()).WithField("grpc.time_ms", durationToMilliseconds(time.Since(start)))
+
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+
+		logByCode(entry, code, "finished streaming call with code "+code.String())
+
+		return err
+	}
+}
+
+func newLoggerForCall(ctx context.Context, logger logging.Logger, fullMethod string, start time.Time) context.Context {
+	fields := logging.Fields{
+		"system":          "grpc",
+		"span.kind":       "server",
+		"grpc.service":    path.Dir(fullMethod)[1:],
+		"grpc.method":     path.Base(fullMethod),
+		"grpc.start_time": start.Format(time.RFC3339),
+	}
+
+	if d, ok := ctx.Deadline(); ok {
+		fields["grpc.request.deadline"] = d.Format(time.RFC3339)
+	}
+
+	return logging.ToContext(ctx, logger.WithFields(fields))
+}
+
+// logByCode logs a message with the level corresponding to a given gRPC code.
+func logByCode(logger logging.Logger, code grpc_codes.Code, msg string) {
+	switch code {
+	case grpc_codes.OK, grpc_codes.Canceled, grpc_codes.InvalidArgument, grpc_codes.NotFound, grpc_codes.AlreadyExists, grpc_codes.Unauthenticated:
+		logger.Info(msg)
+	case grpc_codes.DeadlineExceeded, grpc_codes.PermissionDenied, grpc_codes.ResourceExhausted, grpc_codes.FailedPrecondition, grpc_codes.Aborted, grpc_codes.OutOfRange, grpc_codes.Unavailable:
+		logger.Warn(msg)
+	default:
+		logger.Error(msg)
+	}
+}
+
+func durationToMilliseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()/1000) / 1000
+}
@@ -3,7 +3,7 @@ package interceptors
 import (
 	"context"
 
-	"github.com/0xef53/go-grpc/utils"
+	"github.com/0xef53/go-grpc/requestid"
 
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"google.golang.org/grpc"
@@ -12,15 +12,15 @@ import (
 
 // RequestIdentifierUnaryServerInterceptor returns a unary server interceptor which appends a request ID
 // to the context.
+//
+// The ID is taken from the incoming metadata (see [requestid.MetadataKey]) or generated
+// if it is missing. It is also sent back to the client in the response header.
 func RequestIdentifierUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		reqID := utils.ExtractRequestID(ctx)
+		ctx, reqID := withRequestID(ctx)
 
-		// For logging using ctxlogrus
-		grpc_ctxtags.Extract(ctx).Set("request.uid", reqID)
-
-		// Add to OutgoingMetadata so that the client interceptor can access the request ID.
-		ctx = grpc_metadata.AppendToOutgoingContext(ctx, "request-id", reqID)
+		// Echo back to the client
+		grpc.SetHeader(ctx, grpc_metadata.Pairs(requestid.MetadataKey(), reqID))
 
 		return handler(ctx, req)
 	}
@@ -38,18 +38,43 @@ func (s *wrappedStream) Context() context.Context {
 
 // RequestIdentifierStreamServerInterceptor returns a stream server interceptor which appends a request ID
 // to the context.
+//
+// The ID is taken from the incoming metadata (see [requestid.MetadataKey]) or generated
+// if it is missing. It is also sent back to the client in the response header.
 func RequestIdentifierStreamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		ctx := ss.Context()
+		ctx, reqID := withRequestID(ss.Context())
+
+		// Echo back to the client
+		ss.SetHeader(grpc_metadata.Pairs(requestid.MetadataKey(), reqID))
 
-		reqID := utils.ExtractRequestID(ctx)
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
 
-		// For logging using ctxlogrus
-		grpc_ctxtags.Extract(ctx).Set("request.uid", reqID)
+func withRequestID(ctx context.Context) (context.Context, string) {
+	var reqID string
 
-		// Add to OutgoingMetadata so that the client interceptor can access the request ID.
-		ctx = grpc_metadata.AppendToOutgoingContext(ctx, "request-id", reqID)
+	key := requestid.MetadataKey()
 
-		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	if md, ok := grpc_metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(key); len(v) > 0 && len(v[0]) > 0 {
+			reqID = requestid.Compact(v[0])
+		}
 	}
+
+	if len(reqID) == 0 {
+		reqID = requestid.New()
+	}
+
+	// For logging using logging.Extract()
+	tags := grpc_ctxtags.Extract(ctx).Set("request.uid", reqID)
+
+	if parent := requestid.Parent(reqID); len(parent) > 0 {
+		tags.Set("request.root_uid", requestid.Root(reqID))
+		tags.Set("request.parent_uid", parent)
+	}
+
+	// Add to OutgoingMetadata so that the client interceptor can access the request ID.
+	return grpc_metadata.AppendToOutgoingContext(ctx, key, reqID), reqID
 }
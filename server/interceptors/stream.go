@@ -0,0 +1,81 @@
+package interceptors
+
+import (
+	"sync/atomic"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// accountedServerStream wraps [grpc.ServerStream] to count sent and received
+// messages and bytes. If logMessages is true, the tags of each message are logged.
+type accountedServerStream struct {
+	grpc.ServerStream
+
+	logMessages bool
+
+	sentMsgs  atomic.Int64
+	sentBytes atomic.Int64
+	recvMsgs  atomic.Int64
+	recvBytes atomic.Int64
+}
+
+func (s *accountedServerStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	s.sentMsgs.Add(1)
+	s.sentBytes.Add(int64(payloadSize(m)))
+
+	if s.logMessages {
+		fields := make(logging.Fields)
+
+		for k, v := range tagsFromPayload(m) {
+			fields["grpc.response."+k] = v
+		}
+
+		ctx := s.Context()
+
+		logging.Extract(ctx).WithContext(ctx).WithFields(fields).Info("GRPC Stream message sent")
+	}
+
+	return nil
+}
+
+func (s *accountedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.recvMsgs.Add(1)
+	s.recvBytes.Add(int64(payloadSize(m)))
+
+	if s.logMessages {
+		ctx := s.Context()
+
+		logging.Extract(ctx).WithContext(ctx).WithFields(tagsFromPayload(m)).Info("GRPC Stream message received")
+	}
+
+	return nil
+}
+
+// fields returns the stream counters as log fields.
+func (s *accountedServerStream) fields() logging.Fields {
+	return logging.Fields{
+		"grpc.stream.sent_msgs":  s.sentMsgs.Load(),
+		"grpc.stream.sent_bytes": s.sentBytes.Load(),
+		"grpc.stream.recv_msgs":  s.recvMsgs.Load(),
+		"grpc.stream.recv_bytes": s.recvBytes.Load(),
+	}
+}
+
+func payloadSize(m interface{}) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+
+	return 0
+}
@@ -0,0 +1,122 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/0xef53/go-grpc/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testServerStream is a server stream that receives the given messages
+// and fails on sending after sendLimit messages.
+type testServerStream struct {
+	grpc.ServerStream
+
+	ctx       context.Context
+	recv      []proto.Message
+	sendLimit int
+	sent      int
+}
+
+func (s *testServerStream) Context() context.Context { return s.ctx }
+
+func (s *testServerStream) SendMsg(m interface{}) error {
+	if s.sent >= s.sendLimit {
+		return errors.New("stream is closed")
+	}
+
+	s.sent++
+
+	return nil
+}
+
+func (s *testServerStream) RecvMsg(m interface{}) error {
+	if len(s.recv) == 0 {
+		return errors.New("no more messages")
+	}
+
+	proto.Merge(m.(proto.Message), s.recv[0])
+
+	s.recv = s.recv[1:]
+
+	return nil
+}
+
+func TestAccountedServerStream(t *testing.T) {
+	logger, hook := newTestLogger()
+
+	ss := &testServerStream{
+		ctx:       logging.ToContext(context.Background(), logger),
+		recv:      []proto.Message{wrapperspb.String("first"), wrapperspb.String("second")},
+		sendLimit: 1,
+	}
+
+	stream := &accountedServerStream{ServerStream: ss, logMessages: true}
+
+	for i := 0; i < 3; i++ {
+		stream.RecvMsg(new(wrapperspb.StringValue))
+	}
+
+	reply := wrapperspb.String("reply")
+
+	if err := stream.SendMsg(reply); err != nil {
+		t.Fatal(err)
+	}
+
+	// Failed messages are not counted
+	if err := stream.SendMsg(reply); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	fields := stream.fields()
+
+	want := logging.Fields{
+		"grpc.stream.sent_msgs":  int64(1),
+		"grpc.stream.sent_bytes": int64(proto.Size(reply)),
+		"grpc.stream.recv_msgs":  int64(2),
+		"grpc.stream.recv_bytes": int64(proto.Size(wrapperspb.String("first")) + proto.Size(wrapperspb.String("second"))),
+	}
+
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("got %s = %v, want %v", k, fields[k], v)
+		}
+	}
+
+	entries := hook.AllEntries()
+
+	if len(entries) != 3 {
+		t.Fatalf("got %d message log lines, want 3", len(entries))
+	}
+
+	if entries[0].Data["value"] != "first" || entries[2].Data["grpc.response.value"] != "reply" {
+		t.Errorf("unexpected message tags: %v, %v", entries[0].Data, entries[2].Data)
+	}
+}
+
+func TestLogRequestStreamCompletion(t *testing.T) {
+	logger, hook := newTestLogger()
+
+	ss := &testServerStream{ctx: logging.ToContext(context.Background(), logger), sendLimit: 10}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		stream.SendMsg(wrapperspb.String("reply"))
+
+		return nil
+	}
+
+	if err := LogRequestStreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Stream"}, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	last := hook.LastEntry()
+
+	if last == nil || last.Data["grpc.code"] != "OK" || last.Data["grpc.stream.sent_msgs"] != int64(1) {
+		t.Fatalf("unexpected completion line: %v", last)
+	}
+}
@@ -7,24 +7,36 @@ import (
 	"net"
 	"runtime"
 
+	"github.com/0xef53/go-grpc/logging"
 	"github.com/0xef53/go-grpc/server/interceptors"
 
+	// Register the supported compressors
+	_ "github.com/0xef53/go-grpc/encoding/zstd"
+	_ "google.golang.org/grpc/encoding/gzip"
+
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/stats"
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 )
 
-var logger = log.StandardLogger().WithField("subsystem", "server")
+var logger = logging.NewLogrusLogger(log.StandardLogger().WithField("subsystem", "server"))
 
 // SetLogger sets the global logger used by the package's entities.
 // It should be called during initialization, and it is strongly recommended
 // not to change it afterward.
 func SetLogger(entry *log.Entry) {
-	logger = entry
+	logger = logging.NewLogrusLogger(entry)
+}
+
+// SetLoggerAdapter is like [SetLogger] but accepts any [logging.Logger]
+// implementation, e.g. the one returned by [logging.NewSlogLogger].
+func SetLoggerAdapter(l logging.Logger) {
+	logger = l
 }
 
 // Server represents a gRPC server that handles incoming requests.
@@ -36,6 +48,12 @@ type Server struct {
 
 	buckets []string
 
+	health *health.Server
+	drain  *drainState
+	conns  *connTracker
+
+	admin *adminServer
+
 	group *errgroup.Group
 }
 
@@ -48,17 +66,25 @@ func NewServer(cfg *Config, tlsConfig *tls.Config, ui []grpc.UnaryServerIntercep
 	}
 
 	s := &Server{
-		config:     cfg,
-		tlsConfig:  tlsConfig,
-		grpcServer: newServer(ui, si, tlsConfig),
-		buckets:    []string{defaultServiceBucket},
-		group:      new(errgroup.Group),
+		config:    cfg,
+		tlsConfig: tlsConfig,
+		buckets:   []string{defaultServiceBucket},
+		health:    health.NewServer(),
+		drain:     newDrainState(),
+		conns:     newConnTracker(),
+		group:     new(errgroup.Group),
 	}
 
+	s.grpcServer = newServer(cfg, ui, si, tlsConfig, s.drain.check, s.accessDecider(), s.conns)
+
 	if runtime.GOOS == "linux" {
 		s.config.GRPCSocketPath = "@" + s.config.GRPCSocketPath
 	}
 
+	if len(cfg.AdminBinding) > 0 {
+		s.admin = newAdminServer(s)
+	}
+
 	return s, nil
 }
 
@@ -73,33 +99,62 @@ func (s *Server) SetServiceBuckets(names ...string) {
 // listenAndServe starts the gRPC server and serves services corresponding
 // to the given list of buckets.
 func (s *Server) listenAndServe(ctx context.Context) error {
-	for _, svc := range Services(s.buckets...) {
-		logger.Info("Registering service: ", svc.Name())
-
-		svc.RegisterGRPC(s.grpcServer)
-	}
+	s.registerServices()
 
 	listeners, err := s.config.GetListeners()
 	if err != nil {
 		return err
 	}
 
+	// Keep the raw connections to be able to close them
+	for idx := range listeners {
+		listeners[idx] = s.conns.listener(listeners[idx])
+	}
+
+	// Close the opened listeners if the server cannot be started
+	closeListeners := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+
 	// Default GRPC on Unix Socket
 	if l, err := net.Listen("unix", s.config.GRPCSocketPath); err == nil {
 		defer l.Close()
 
 		listeners = append(listeners, l)
 	} else {
+		closeListeners()
+
 		return err
 	}
 
+	var adminListener net.Listener
+
+	if s.admin != nil {
+		if adminListener, err = s.config.GetAdminListener(); err != nil {
+			closeListeners()
+
+			return err
+		}
+	}
+
 	group, groupCtx := errgroup.WithContext(ctx)
 
+	if adminListener != nil {
+		group.Go(func() error {
+			return s.admin.serve(groupCtx, adminListener)
+		})
+	}
+
 	idleConnsClosed := make(chan struct{})
 
 	go func() {
 		<-groupCtx.Done()
 
+		// Let the health checking clients know about the shutdown
+		s.health.Shutdown()
+
 		s.grpcServer.GracefulStop()
 
 		close(idleConnsClosed)
@@ -109,14 +164,14 @@ func (s *Server) listenAndServe(ctx context.Context) error {
 		listener := l
 
 		group.Go(func() error {
-			logger.WithFields(log.Fields{"addr": listener.Addr().String()}).Info("Starting GRPC server")
+			logger.WithFields(logging.Fields{"addr": listener.Addr().String()}).Info("Starting GRPC server")
 
 			if err := s.grpcServer.Serve(listener); err != nil {
 				// Error starting or closing listener
 				return err
 			}
 
-			logger.WithFields(log.Fields{"addr": listener.Addr().String()}).Info("GRPC server stopped")
+			logger.WithFields(logging.Fields{"addr": listener.Addr().String()}).Info("GRPC server stopped")
 
 			return nil
 		})
@@ -144,33 +199,68 @@ func (s *Server) Wait() error {
 	return s.group.Wait()
 }
 
+// interceptorsLogger always refers to the current package logger,
+// so that SetLogger also affects the default interceptors.
+var interceptorsLogger = logging.NewDeferredLogger(func() logging.Logger { return logger })
+
 var DefaultUnaryInterceptors = []grpc.UnaryServerInterceptor{
 	interceptors.TagsUnaryServerInterceptor(),
 	interceptors.RequestIdentifierUnaryServerInterceptor(),
-	grpc_logrus.UnaryServerInterceptor(logger),
+	interceptors.LoggerUnaryServerInterceptor(interceptorsLogger),
+	interceptors.ErrorMappingUnaryServerInterceptor(),
 }
 
 var DefaultStreamInterceptors = []grpc.StreamServerInterceptor{
 	interceptors.TagsStreamServerInterceptor(),
 	interceptors.RequestIdentifierStreamServerInterceptor(),
-	grpc_logrus.StreamServerInterceptor(logger),
+	interceptors.LoggerStreamServerInterceptor(interceptorsLogger),
+	interceptors.ErrorMappingStreamServerInterceptor(),
 }
 
 // newServer returns a new grpc.Server instance with a preconfigured list of interceptors.
-func newServer(ui []grpc.UnaryServerInterceptor, si []grpc.StreamServerInterceptor, tlsConfig *tls.Config) *grpc.Server {
-	_ui := append(DefaultUnaryInterceptors, ui...)
+//
+// The drain decider is used to reject new calls in drain mode.
+// The access decider, if not nil, is used to reject calls from disallowed addresses.
+// The stats handler is used to track the client connections.
+func newServer(cfg *Config, ui []grpc.UnaryServerInterceptor, si []grpc.StreamServerInterceptor, tlsConfig *tls.Config, drain interceptors.DrainDecider, access interceptors.AccessDecider, sh stats.Handler) *grpc.Server {
+	logOpts := []interceptors.LogOption{
+		interceptors.WithResponseLogging(cfg.logResponsesDecider()),
+	}
+
+	if cfg.LogStreamMessages {
+		logOpts = append(logOpts, interceptors.WithStreamMessageLogging(nil))
+	}
+
+	_ui := append([]grpc.UnaryServerInterceptor{}, DefaultUnaryInterceptors...)
+
+	if access != nil {
+		_ui = append(_ui, interceptors.AccessControlUnaryServerInterceptor(access))
+	}
+
+	_ui = append(_ui, interceptors.DrainUnaryServerInterceptor(drain))
+	_ui = append(_ui, interceptors.CompressionUnaryServerInterceptor(cfg.Compression))
+	_ui = append(_ui, ui...)
 
 	// Add after the "ui" to allow changes in "grpc_ctxtags"
-	_ui = append(_ui, interceptors.LogRequestUnaryServerInterceptor())
+	_ui = append(_ui, interceptors.LogRequestUnaryServerInterceptor(logOpts...))
+
+	_si := append([]grpc.StreamServerInterceptor{}, DefaultStreamInterceptors...)
+
+	if access != nil {
+		_si = append(_si, interceptors.AccessControlStreamServerInterceptor(access))
+	}
 
-	_si := append(DefaultStreamInterceptors, si...)
+	_si = append(_si, interceptors.DrainStreamServerInterceptor(drain))
+	_si = append(_si, interceptors.CompressionStreamServerInterceptor(cfg.Compression))
+	_si = append(_si, si...)
 
 	// Add after the "si" to allow changes in "grpc_ctxtags"
-	_si = append(_si, interceptors.LogRequestStreamServerInterceptor())
+	_si = append(_si, interceptors.LogRequestStreamServerInterceptor(logOpts...))
 
 	opts := []grpc.ServerOption{
 		grpc_middleware.WithUnaryServerChain(_ui...),
 		grpc_middleware.WithStreamServerChain(_si...),
+		grpc.StatsHandler(sh),
 	}
 
 	if tlsConfig != nil {
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"sort"
 	"sync"
 
 	grpc_runtime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -82,3 +83,19 @@ func Services(buckets ...string) []Service {
 
 	return services
 }
+
+// Buckets returns a sorted list of all known bucket names.
+func Buckets() []string {
+	pool.Lock()
+	defer pool.Unlock()
+
+	names := make([]string, 0, len(pool.services))
+
+	for name := range pool.services {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
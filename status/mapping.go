@@ -0,0 +1,146 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+// mapping returns the gRPC code and the client-facing message for a given error.
+// It returns false if it does not handle the error.
+type mapping func(error) (grpc_codes.Code, string, bool)
+
+var mappings = struct {
+	sync.RWMutex
+	funcs []mapping
+}{}
+
+// RegisterError maps a given sentinel error to a gRPC code.
+// An error matches if errors.Is(err, target) reports true.
+//
+// The message of the resulting status is the message of the target,
+// so the wrapping context is not exposed to the client.
+func RegisterError(target error, code grpc_codes.Code) {
+	register(func(err error) (grpc_codes.Code, string, bool) {
+		return code, target.Error(), errors.Is(err, target)
+	})
+}
+
+// RegisterErrorType maps errors of type T to a gRPC code.
+// An error matches if errors.As(err, *T) reports true.
+//
+// The message of the resulting status is the message of the matched error of type T.
+func RegisterErrorType[T error](code grpc_codes.Code) {
+	register(func(err error) (grpc_codes.Code, string, bool) {
+		var target T
+
+		if errors.As(err, &target) {
+			return code, target.Error(), true
+		}
+
+		return code, "", false
+	})
+}
+
+// RegisterErrorFunc registers a function that maps errors to gRPC codes.
+// The function should return false if it does not handle a given error.
+//
+// The message of the resulting status is a fixed message for the code.
+//
+// Mappings are checked in the order of their registration.
+func RegisterErrorFunc(fn func(error) (grpc_codes.Code, bool)) {
+	register(func(err error) (grpc_codes.Code, string, bool) {
+		code, ok := fn(err)
+
+		return code, codeMessage(code), ok
+	})
+}
+
+func register(fn mapping) {
+	mappings.Lock()
+	defer mappings.Unlock()
+
+	mappings.funcs = append(mappings.funcs, fn)
+}
+
+// lookup returns the gRPC code and the client-facing message a given error is mapped to.
+func lookup(err error) (grpc_codes.Code, string, bool) {
+	mappings.RLock()
+	defer mappings.RUnlock()
+
+	for _, fn := range mappings.funcs {
+		if code, msg, ok := fn(err); ok {
+			return code, msg, true
+		}
+	}
+
+	return grpc_codes.Unknown, "", false
+}
+
+// Code returns the gRPC code a given error is mapped to.
+// The second return value reports whether a mapping was found.
+func Code(err error) (grpc_codes.Code, bool) {
+	code, _, ok := lookup(err)
+
+	return code, ok
+}
+
+// FromError converts a given error to a gRPC status error using the registered mappings.
+//
+// Only the message of the mapped error is used as the status message,
+// the full error chain should be logged on the server side.
+//
+// Errors that already carry a gRPC status and errors without a mapping
+// are returned as is. The second return value reports whether the error was mapped.
+func FromError(ctx context.Context, err error) (error, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	if _, ok := grpc_status.FromError(err); ok {
+		return err, false
+	}
+
+	if code, msg, ok := lookup(err); ok {
+		return Error(ctx, code, msg), true
+	}
+
+	return err, false
+}
+
+// codeMessage returns a fixed client-facing message for a given code.
+func codeMessage(code grpc_codes.Code) string {
+	switch code {
+	case grpc_codes.Canceled:
+		return "request canceled"
+	case grpc_codes.InvalidArgument:
+		return "invalid argument"
+	case grpc_codes.DeadlineExceeded:
+		return "deadline exceeded"
+	case grpc_codes.NotFound:
+		return "not found"
+	case grpc_codes.AlreadyExists:
+		return "already exists"
+	case grpc_codes.PermissionDenied:
+		return "permission denied"
+	case grpc_codes.ResourceExhausted:
+		return "resource exhausted"
+	case grpc_codes.FailedPrecondition:
+		return "failed precondition"
+	case grpc_codes.Aborted:
+		return "aborted"
+	case grpc_codes.OutOfRange:
+		return "out of range"
+	case grpc_codes.Unimplemented:
+		return "not implemented"
+	case grpc_codes.Unavailable:
+		return "service unavailable"
+	case grpc_codes.Unauthenticated:
+		return "unauthenticated"
+	}
+
+	return "internal error"
+}
@@ -0,0 +1,138 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xef53/go-grpc/utils"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// New returns a [grpc_status.Status] with a given code, message and error details.
+//
+// If the context carries a request ID, it is added to the details
+// as [errdetails.RequestInfo].
+func New(ctx context.Context, code grpc_codes.Code, msg string, details ...protoadapt.MessageV1) *grpc_status.Status {
+	st := grpc_status.New(code, msg)
+
+	if ctx != nil {
+		if reqID, ok := utils.RequestIDFromContext(ctx); ok {
+			details = append(details, &errdetails.RequestInfo{RequestId: reqID})
+		}
+	}
+
+	if len(details) == 0 {
+		return st
+	}
+
+	if v, err := st.WithDetails(details...); err == nil {
+		return v
+	}
+
+	// Details cannot be marshaled, so return the status as is
+	return st
+}
+
+// Error returns an error representing a given code, message and error details.
+// See [New] for details.
+func Error(ctx context.Context, code grpc_codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	return New(ctx, code, msg, details...).Err()
+}
+
+// Errorf returns an error representing a given code and formatted message.
+// See [New] for details.
+func Errorf(ctx context.Context, code grpc_codes.Code, format string, args ...interface{}) error {
+	return New(ctx, code, fmt.Sprintf(format, args...)).Err()
+}
+
+// BadRequest returns [errdetails.BadRequest] with a given list of field violations.
+func BadRequest(violations ...*errdetails.BadRequest_FieldViolation) *errdetails.BadRequest {
+	return &errdetails.BadRequest{
+		FieldViolations: violations,
+	}
+}
+
+// FieldViolation returns a single field violation for [BadRequest].
+func FieldViolation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	}
+}
+
+// ErrorInfo returns [errdetails.ErrorInfo] describing the cause of the error.
+func ErrorInfo(reason, domain string, metadata map[string]string) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: metadata,
+	}
+}
+
+// RetryInfo returns [errdetails.RetryInfo] telling the client how long to wait
+// before retrying the request.
+func RetryInfo(delay time.Duration) *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(delay),
+	}
+}
+
+// ResourceInfo returns [errdetails.ResourceInfo] describing the resource being accessed.
+func ResourceInfo(resourceType, resourceName, owner, description string) *errdetails.ResourceInfo {
+	return &errdetails.ResourceInfo{
+		ResourceType: resourceType,
+		ResourceName: resourceName,
+		Owner:        owner,
+		Description:  description,
+	}
+}
+
+// LocalizedMessage returns [errdetails.LocalizedMessage] with the error message
+// in a given locale (e.g. "en-US").
+func LocalizedMessage(locale, msg string) *errdetails.LocalizedMessage {
+	return &errdetails.LocalizedMessage{
+		Locale:  locale,
+		Message: msg,
+	}
+}
+
+// RequestID returns the request ID stored in the details of a given error.
+func RequestID(err error) (string, bool) {
+	for _, d := range grpc_status.Convert(err).Details() {
+		if v, ok := d.(*errdetails.RequestInfo); ok {
+			return v.RequestId, true
+		}
+	}
+
+	return "", false
+}
+
+// RetryDelay returns the retry delay stored in the details of a given error.
+func RetryDelay(err error) (time.Duration, bool) {
+	for _, d := range grpc_status.Convert(err).Details() {
+		if v, ok := d.(*errdetails.RetryInfo); ok && v.RetryDelay != nil {
+			return v.RetryDelay.AsDuration(), true
+		}
+	}
+
+	return 0, false
+}
+
+// FieldViolations returns the field violations stored in the details of a given error.
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0)
+
+	for _, d := range grpc_status.Convert(err).Details() {
+		if v, ok := d.(*errdetails.BadRequest); ok {
+			violations = append(violations, v.FieldViolations...)
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,90 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	grpc_codes "google.golang.org/grpc/codes"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+var errNotFound = errors.New("object not found")
+
+type quotaError struct {
+	limit int
+}
+
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("quota exceeded: limit = %d", e.limit)
+}
+
+func TestErrorMapping(t *testing.T) {
+	RegisterError(errNotFound, grpc_codes.NotFound)
+	RegisterErrorType[*quotaError](grpc_codes.ResourceExhausted)
+	RegisterErrorFunc(func(err error) (grpc_codes.Code, bool) {
+		return grpc_codes.PermissionDenied, err.Error() == "forbidden"
+	})
+
+	ctx := grpc_metadata.NewIncomingContext(context.Background(), grpc_metadata.Pairs("request-id", "ABCDEF"))
+
+	type value struct {
+		Err        error
+		Want       grpc_codes.Code
+		WantMsg    string
+		WantMapped bool
+	}
+
+	// The wrapping context must not be exposed in the status message
+	values := []value{
+		{fmt.Errorf("cannot get user /etc/users/1: %w", errNotFound), grpc_codes.NotFound, "object not found", true},
+		{fmt.Errorf("cannot create user: %w", &quotaError{limit: 10}), grpc_codes.ResourceExhausted, "quota exceeded: limit = 10", true},
+		{errors.New("forbidden"), grpc_codes.PermissionDenied, "permission denied", true},
+		{grpc_status.Error(grpc_codes.Aborted, "aborted"), grpc_codes.Aborted, "aborted", false},
+		{errors.New("unexpected error"), grpc_codes.Unknown, "unexpected error", false},
+	}
+
+	for idx, v := range values {
+		err, mapped := FromError(ctx, v.Err)
+
+		if mapped != v.WantMapped {
+			t.Fatalf("got invalid mapped flag (idx == %d): want %t, got %t", idx, v.WantMapped, mapped)
+		}
+
+		if got := grpc_status.Code(err); got != v.Want {
+			t.Fatalf("got invalid code (idx == %d):\nwant:\t%s\ngot:\t%s", idx, v.Want, got)
+		}
+
+		if got := grpc_status.Convert(err).Message(); got != v.WantMsg {
+			t.Fatalf("got invalid message (idx == %d):\nwant:\t%q\ngot:\t%q", idx, v.WantMsg, got)
+		}
+	}
+
+	err, _ := FromError(ctx, errNotFound)
+
+	if reqID, _ := RequestID(err); reqID != "ABCDEF" {
+		t.Fatalf("got invalid request ID: %q", reqID)
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	err := Error(context.Background(), grpc_codes.InvalidArgument, "invalid request",
+		BadRequest(FieldViolation("name", "must not be empty")),
+		RetryInfo(3*time.Second),
+	)
+
+	if v := FieldViolations(err); len(v) != 1 || v[0].Field != "name" {
+		t.Fatalf("got invalid field violations: %v", v)
+	}
+
+	if d, ok := RetryDelay(err); !ok || d != 3*time.Second {
+		t.Fatalf("got invalid retry delay: %s", d)
+	}
+
+	if _, ok := RequestID(err); ok {
+		t.Fatalf("got unexpected request ID")
+	}
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultPort is the port of the gRPC server used if the target does not specify one.
+const DefaultPort = "9191"
+
+// NormalizeTarget converts a given string into a gRPC target.
+//
+// The following forms are supported:
+//   - "unix:/path/to.sock", "unix:///path/to.sock" and "unix-abstract:name" are used as is;
+//   - "/path/to.sock" is converted to "unix:/path/to.sock";
+//   - "@name" is converted to "unix-abstract:name";
+//   - "dns:///host[:port]" and "dns://authority/host[:port]" get the default port if omitted;
+//   - other targets with a scheme, e.g. "file:///etc/app/endpoints.json", are used as is;
+//   - everything else is considered a "host[:port]" pair (see [NormalizeHostport]).
+func NormalizeTarget(target string) string {
+	target = strings.TrimSpace(target)
+
+	switch {
+	case strings.HasPrefix(target, "/"):
+		return "unix:" + target
+	case strings.HasPrefix(target, "@"):
+		return "unix-abstract:" + target[1:]
+	case !HasScheme(target):
+		return NormalizeHostport(target)
+	}
+
+	// dns://[authority]/host[:port]
+	if rest, ok := strings.CutPrefix(target, "dns://"); ok {
+		if authority, endpoint, ok := strings.Cut(rest, "/"); ok && len(endpoint) > 0 {
+			return "dns://" + authority + "/" + NormalizeHostport(endpoint)
+		}
+	}
+
+	return target
+}
+
+var schemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*$`)
+
+// HasScheme reports whether a given target starts with a scheme, e.g. "unix:"
+// or "dns://", rather than being a "host:port" pair.
+func HasScheme(target string) bool {
+	target = strings.TrimSpace(target)
+
+	if strings.HasPrefix(target, "unix:") || strings.HasPrefix(target, "unix-abstract:") {
+		return true
+	}
+
+	scheme, _, ok := strings.Cut(target, "://")
+
+	return ok && schemeRe.MatchString(scheme)
+}
+
+// SocketPath returns the path of the unix socket the gRPC server of a given program
+// with a given PID listens on by default (see the GRPCSocketPath field of the server config).
+//
+// On Linux, the server listens on the abstract socket with this name prefixed with '@'.
+func SocketPath(program string, pid int) string {
+	return filepath.Join("/run", fmt.Sprintf("%s_%d.sock", program, pid))
+}
+
+// LocalSocketTarget returns the gRPC target of the default unix socket
+// of a running instance of a given program (see [SocketPath]).
+//
+// On Linux, the abstract sockets are looked up in /proc/net/unix, on other
+// systems the socket files are looked up in /run. An error is returned
+// if no running instance or more than one is found.
+func LocalSocketTarget(program string) (string, error) {
+	var paths []string
+	var err error
+
+	if runtime.GOOS == "linux" {
+		paths, err = abstractSockets()
+	} else {
+		paths, err = filepath.Glob(filepath.Join("/run", program+"_*.sock"))
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	prefix := strings.TrimSuffix(SocketPath(program, 0), "0.sock")
+
+	var found []string
+
+	for _, p := range paths {
+		pid, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(p, prefix), ".sock"))
+		if err != nil || !strings.HasPrefix(p, prefix) || p != SocketPath(program, pid) {
+			continue
+		}
+
+		if !processExists(pid) {
+			continue
+		}
+
+		// Accepted connections are listed with the same path
+		if !slices.Contains(found, p) {
+			found = append(found, p)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("no running instance of %s found", program)
+	case 1:
+	default:
+		return "", fmt.Errorf("multiple running instances of %s found: %s", program, strings.Join(found, ", "))
+	}
+
+	if runtime.GOOS == "linux" {
+		return "unix-abstract:" + found[0], nil
+	}
+
+	return "unix:" + found[0], nil
+}
+
+// abstractSockets returns the names of the listening abstract unix sockets
+// without the '@' prefix.
+func abstractSockets() ([]string, error) {
+	f, err := os.Open("/proc/net/unix")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var names []string
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		// Num RefCount Protocol Flags Type St Inode Path
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 8 && strings.HasPrefix(fields[7], "@") {
+			names = append(names, fields[7][1:])
+		}
+	}
+
+	return names, scanner.Err()
+}
+
+func processExists(pid int) bool {
+	if runtime.GOOS == "linux" {
+		_, err := os.Stat(fmt.Sprintf("/proc/%d", pid))
+
+		return err == nil
+	}
+
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// Signal 0 only checks the existence of the process
+	return p.Signal(syscall.Signal(0)) == nil
+}
@@ -2,33 +2,133 @@ package utils
 
 import (
 	"context"
+	crypto_rand "crypto/rand"
+	"crypto/subtle"
 	"math/rand"
 	"net"
 	"slices"
 	"strings"
 	"time"
 
+	"github.com/0xef53/go-grpc/requestid"
+
+	"google.golang.org/grpc/credentials"
 	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_peer "google.golang.org/grpc/peer"
 )
 
-// ExtractRequestID tries to extract the request ID from the outgoing gRPC context.
-// If no request ID is found in the outgoing metadata, the function generates a new one
-// using [NewRequestID()].
+// ExtractRequestID tries to extract the request ID from the incoming gRPC context.
+// If no request ID is found in the incoming metadata, the function generates a new one
+// using [requestid.New].
 func ExtractRequestID(ctx context.Context) string {
-	reqID := NewRequestID()
+	if md, ok := grpc_metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestid.MetadataKey()); len(v) > 0 {
+			return v[0]
+		}
+	}
+
+	return requestid.New()
+}
+
+// RequestIDFromContext returns the request ID of the current call.
+//
+// On the server side, the ID is taken from the outgoing metadata, where it is placed
+// by the request identifier interceptor, or from the incoming metadata otherwise.
+// The second return value reports whether the ID was found.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	key := requestid.MetadataKey()
+
+	if md, ok := grpc_metadata.FromOutgoingContext(ctx); ok {
+		if v := md.Get(key); len(v) > 0 {
+			return v[len(v)-1], true
+		}
+	}
 
 	if md, ok := grpc_metadata.FromIncomingContext(ctx); ok {
-		if v, ok := md["request-id"]; ok {
-			reqID = v[0]
+		if v := md.Get(key); len(v) > 0 {
+			return v[0], true
 		}
 	}
 
-	return reqID
+	return "", false
+}
+
+const gatewayTokenKey = "x-gateway-token"
+
+// gatewayToken marks the calls of the gRPC Gateway running in this process.
+// It is random, so other processes connected to the Unix socket cannot forge it.
+var gatewayToken = crypto_rand.Text()
+
+// WithGatewayToken returns a context with the outgoing metadata that marks the call
+// as made by the gRPC Gateway running in this process (see [ClientAddr]).
+func WithGatewayToken(ctx context.Context) context.Context {
+	return grpc_metadata.AppendToOutgoingContext(ctx, gatewayTokenKey, gatewayToken)
+}
+
+// fromGateway reports whether the current call was made by the gRPC Gateway
+// running in this process.
+func fromGateway(md grpc_metadata.MD) bool {
+	for _, v := range md.Get(gatewayTokenKey) {
+		if subtle.ConstantTimeCompare([]byte(v), []byte(gatewayToken)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientAddr returns the network address of the client of the current call.
+//
+// If the call was received from the gRPC Gateway running in this process
+// via a Unix socket (see [WithGatewayToken]), the address is taken from the last entry
+// of the "x-forwarded-for" metadata, which is the address the gateway received
+// the HTTP request from. The metadata of other clients is ignored.
+func ClientAddr(ctx context.Context) (net.Addr, bool) {
+	p, ok := grpc_peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil, false
+	}
+
+	if _, ok := p.Addr.(*net.UnixAddr); !ok {
+		return p.Addr, true
+	}
+
+	if md, ok := grpc_metadata.FromIncomingContext(ctx); ok && fromGateway(md) {
+		if v := md.Get("x-forwarded-for"); len(v) > 0 {
+			entries := strings.Split(v[len(v)-1], ",")
+
+			if ip := net.ParseIP(strings.TrimSpace(entries[len(entries)-1])); ip != nil {
+				return &net.TCPAddr{IP: ip}, true
+			}
+		}
+	}
+
+	return p.Addr, true
+}
+
+// PeerIdentity returns the subject common name of the TLS certificate
+// presented by the client of the current call.
+// The second return value reports whether the identity was found.
+func PeerIdentity(ctx context.Context) (string, bool) {
+	p, ok := grpc_peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		if certs := tlsInfo.State.PeerCertificates; len(certs) > 0 {
+			return certs[0].Subject.CommonName, true
+		}
+	}
+
+	return "", false
 }
 
 // NewRequestID generates a new request ID.
+//
+// Deprecated: use [requestid.New] instead.
 func NewRequestID() string {
-	return RandString(6)
+	return requestid.New()
 }
 
 // RandString returns a random generated string with given length.
@@ -42,34 +142,22 @@ func RandString(length int) string {
 	return string(strb)
 }
 
-// NormalizeHostport normalizes a hostport string by ensuring it has a port number.
+// NormalizeHostport normalizes a hostport string by ensuring it has a port number
+// ([DefaultPort] if omitted). IPv6 addresses, including the ones with a zone
+// (e.g. "fe80::1%eth0"), are enclosed in square brackets.
 func NormalizeHostport(hostport string) string {
 	hostport = strings.TrimSpace(hostport)
 
-	switch n := len(strings.Split(hostport, ":")); {
-	case n == 1:
-		// IPv4 without port
-		return hostport + ":9191"
-	case n == 2:
-		// IPv4 with port
-		return hostport
-	}
-
-	// IPv6 probably
-
-	if strings.HasPrefix(hostport, "[") {
-		switch parts := strings.Split(hostport, "]"); len(parts) {
-		case 1:
-			return hostport + ":9191"
-		case 2:
-			if len(parts[1]) == 0 {
-				return hostport + ":9191"
-			}
-			return hostport
+	if host, port, err := net.SplitHostPort(hostport); err == nil {
+		if len(port) == 0 {
+			port = DefaultPort
 		}
+
+		return net.JoinHostPort(host, port)
 	}
 
-	return "[" + hostport + "]:9191"
+	// No port or IPv6 without brackets
+	return net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(hostport, "["), "]"), DefaultPort)
 }
 
 // ParseBindings converts a string slice of IP addresses and interface names
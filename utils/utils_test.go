@@ -1,7 +1,16 @@
 package utils
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
 	"testing"
+
+	"google.golang.org/grpc/credentials"
+	grpc_metadata "google.golang.org/grpc/metadata"
+	grpc_peer "google.golang.org/grpc/peer"
 )
 
 func TestHostportNormalization(t *testing.T) {
@@ -20,6 +29,9 @@ func TestHostportNormalization(t *testing.T) {
 		{"[2a01::]:1234 ", "[2a01::]:1234"},
 		{" [2a01::]   ", "[2a01::]:9191"},
 		{"2a01::   ", "[2a01::]:9191"},
+		{"fe80::1%eth0", "[fe80::1%eth0]:9191"},
+		{"[fe80::1%eth0]:1234", "[fe80::1%eth0]:1234"},
+		{"localhost:", "localhost:9191"},
 	}
 
 	for idx, v := range values {
@@ -29,3 +41,76 @@ func TestHostportNormalization(t *testing.T) {
 		}
 	}
 }
+
+func TestTargetNormalization(t *testing.T) {
+	type value struct {
+		Orig string
+		Want string
+	}
+
+	values := []value{
+		{"192.168.0.1", "192.168.0.1:9191"},
+		{"localhost:5555", "localhost:5555"},
+		{"unix:/run/app.sock", "unix:/run/app.sock"},
+		{"unix:///run/app.sock", "unix:///run/app.sock"},
+		{"unix-abstract:app", "unix-abstract:app"},
+		{" /run/app.sock ", "unix:/run/app.sock"},
+		{"@/run/app_1.sock", "unix-abstract:/run/app_1.sock"},
+		{"dns:///example.org", "dns:///example.org:9191"},
+		{"dns://8.8.8.8:53/example.org:5555", "dns://8.8.8.8:53/example.org:5555"},
+		{"dns:///[2a01::1]", "dns:///[2a01::1]:9191"},
+		{"file:///etc/app/endpoints.json", "file:///etc/app/endpoints.json"},
+	}
+
+	for idx, v := range values {
+		got := NormalizeTarget(v.Orig)
+		if got != v.Want {
+			t.Fatalf("got invalid result (idx == %d):\nwant:\t%q\ngot:\t%q", idx, v.Want, got)
+		}
+	}
+}
+
+func TestPeerIdentity(t *testing.T) {
+	state := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "client-1"}}},
+	}
+
+	ctx := grpc_peer.NewContext(context.Background(), &grpc_peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+
+	if v, ok := PeerIdentity(ctx); !ok || v != "client-1" {
+		t.Fatalf("got invalid identity: %q (ok = %t)", v, ok)
+	}
+
+	if v, ok := PeerIdentity(grpc_peer.NewContext(context.Background(), new(grpc_peer.Peer))); ok {
+		t.Fatalf("got unexpected identity: %q", v)
+	}
+}
+
+func TestClientAddr(t *testing.T) {
+	unixPeer := &grpc_peer.Peer{Addr: &net.UnixAddr{Name: "@app.sock", Net: "unix"}}
+
+	forwarded := grpc_metadata.Pairs("x-forwarded-for", "203.0.113.1, 198.51.100.7")
+
+	// The metadata of the calls made by the gateway
+	gatewayMD, _ := grpc_metadata.FromOutgoingContext(WithGatewayToken(context.Background()))
+
+	tests := []struct {
+		peer *grpc_peer.Peer
+		md   grpc_metadata.MD
+		want string
+	}{
+		// A local process must not spoof its address
+		{unixPeer, forwarded, "@app.sock"},
+		{unixPeer, grpc_metadata.Join(forwarded, grpc_metadata.Pairs(gatewayTokenKey, "guess")), "@app.sock"},
+		{unixPeer, grpc_metadata.Join(forwarded, gatewayMD), "198.51.100.7:0"},
+		{&grpc_peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5555}}, grpc_metadata.Join(forwarded, gatewayMD), "10.0.0.1:5555"},
+	}
+
+	for idx, tt := range tests {
+		ctx := grpc_metadata.NewIncomingContext(grpc_peer.NewContext(context.Background(), tt.peer), tt.md)
+
+		if addr, ok := ClientAddr(ctx); !ok || addr.String() != tt.want {
+			t.Errorf("got invalid address (idx == %d): want %s, got %v", idx, tt.want, addr)
+		}
+	}
+}